@@ -0,0 +1,230 @@
+package cfr
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// RunningStat accumulates the mean and variance of a stream of samples
+// using Welford's algorithm.
+type RunningStat struct {
+	N    int
+	Mean float64
+	M2   float64
+}
+
+// Add adds the sample x.
+func (s *RunningStat) Add(x float64) {
+	s.N++
+	delta := x - s.Mean
+	s.Mean += delta / float64(s.N)
+	s.M2 += delta * (x - s.Mean)
+}
+
+// Merge adds all of the samples accumulated in other.
+func (s *RunningStat) Merge(other RunningStat) {
+	if other.N == 0 {
+		return
+	}
+
+	n := s.N + other.N
+	delta := other.Mean - s.Mean
+	s.M2 += other.M2 + delta*delta*float64(s.N)*float64(other.N)/float64(n)
+	s.Mean += delta * float64(other.N) / float64(n)
+	s.N = n
+}
+
+// Variance returns the sample variance, or 0 if there are fewer than 2 samples.
+func (s RunningStat) Variance() float64 {
+	if s.N < 2 {
+		return 0
+	}
+
+	return s.M2 / float64(s.N-1)
+}
+
+// InfoSetDiagnostics holds the statistics of the sampled estimates for one InfoSet.
+//
+// There is one sample per iteration in which the InfoSet's player is the
+// traversing player. It is the sum of the weighted estimates accumulated
+// into the InfoSet's policy during that iteration, or 0 if it was not reached.
+type InfoSetDiagnostics struct {
+	Player  int
+	CFValue RunningStat
+	Regrets []RunningStat
+
+	// Sums of the estimates in the current iteration.
+	cfValueSum float64
+	regretSums []float64
+}
+
+// Diagnostics records statistics of MCCFR traversals, for comparing the
+// variance and cost of different samplers.
+type Diagnostics struct {
+	// NodesTouched is the number of nodes visited in each iteration.
+	NodesTouched []int
+	// WallTime is the duration of each iteration.
+	WallTime []time.Duration
+	// InfoSets holds statistics of the sampled counterfactual values and
+	// regrets for each InfoSet, by key.
+	InfoSets map[string]*InfoSetDiagnostics
+
+	// Number of iterations in which each player has been the traversing player.
+	traversals map[int]int
+	nodes      int
+}
+
+// NewDiagnostics returns a new, empty Diagnostics.
+func NewDiagnostics() *Diagnostics {
+	return &Diagnostics{
+		InfoSets:   make(map[string]*InfoSetDiagnostics),
+		traversals: make(map[int]int),
+	}
+}
+
+// observe records the weighted counterfactual value and regrets
+// accumulated into the given InfoSet's policy.
+func (d *Diagnostics) observe(key []byte, player int, w, cfValue float32, regrets []float32) {
+	is, ok := d.InfoSets[string(key)]
+	if !ok {
+		is = &InfoSetDiagnostics{
+			Player:     player,
+			Regrets:    make([]RunningStat, len(regrets)),
+			regretSums: make([]float64, len(regrets)),
+		}
+
+		d.InfoSets[string(key)] = is
+	}
+
+	is.cfValueSum += float64(w * cfValue)
+	for i, r := range regrets {
+		is.regretSums[i] += float64(w * r)
+	}
+}
+
+// endIteration closes out the samples for the iteration just traversed.
+func (d *Diagnostics) endIteration(traversingPlayer int, elapsed time.Duration) {
+	d.NodesTouched = append(d.NodesTouched, d.nodes)
+	d.WallTime = append(d.WallTime, elapsed)
+	d.nodes = 0
+
+	n := d.traversals[traversingPlayer]
+	for _, is := range d.InfoSets {
+		if is.Player != traversingPlayer {
+			continue
+		}
+
+		// The InfoSet was not reached in any iteration before it was first seen.
+		if is.CFValue.N < n {
+			missed := RunningStat{N: n - is.CFValue.N}
+			is.CFValue.Merge(missed)
+			for i := range is.Regrets {
+				is.Regrets[i].Merge(missed)
+			}
+		}
+
+		is.CFValue.Add(is.cfValueSum)
+		is.cfValueSum = 0
+		for i := range is.Regrets {
+			is.Regrets[i].Add(is.regretSums[i])
+			is.regretSums[i] = 0
+		}
+	}
+
+	d.traversals[traversingPlayer] = n + 1
+}
+
+// DiagnosticsSummary summarizes a Diagnostics.
+type DiagnosticsSummary struct {
+	Iterations       int
+	NumInfoSets      int
+	MeanNodesTouched float64
+	MeanWallTime     time.Duration
+	// Mean over InfoSets of the variance of the counterfactual value estimates.
+	MeanCFValueVariance float64
+	// Mean over InfoSets and actions of the variance of the regret estimates.
+	MeanRegretVariance float64
+}
+
+// Summary returns the averages of the recorded statistics.
+func (d *Diagnostics) Summary() DiagnosticsSummary {
+	s := DiagnosticsSummary{
+		Iterations:  len(d.NodesTouched),
+		NumInfoSets: len(d.InfoSets),
+	}
+
+	if s.Iterations > 0 {
+		var nodes int
+		var wallTime time.Duration
+		for i, n := range d.NodesTouched {
+			nodes += n
+			wallTime += d.WallTime[i]
+		}
+
+		s.MeanNodesTouched = float64(nodes) / float64(s.Iterations)
+		s.MeanWallTime = wallTime / time.Duration(s.Iterations)
+	}
+
+	nRegrets := 0
+	for _, is := range d.InfoSets {
+		s.MeanCFValueVariance += is.CFValue.Variance()
+		for _, r := range is.Regrets {
+			s.MeanRegretVariance += r.Variance()
+			nRegrets++
+		}
+	}
+
+	if s.NumInfoSets > 0 {
+		s.MeanCFValueVariance /= float64(s.NumInfoSets)
+	}
+	if nRegrets > 0 {
+		s.MeanRegretVariance /= float64(nRegrets)
+	}
+
+	return s
+}
+
+// CompareSamplers runs nIter iterations of MCCFR on the given tree with each
+// of the samplers, starting each from a new StrategyProfile, and returns a
+// summary of the diagnostics for each sampler by name.
+func CompareSamplers(root GameTreeNode, newProfile func() StrategyProfile, samplers map[string]Sampler, nIter int) map[string]DiagnosticsSummary {
+	result := make(map[string]DiagnosticsSummary, len(samplers))
+	for name, sampler := range samplers {
+		profile := newProfile()
+		c := NewMCCFR(profile, sampler)
+		d := NewDiagnostics()
+		c.SetDiagnostics(d)
+		for i := 0; i < nIter; i++ {
+			c.Run(root)
+			profile.Update()
+		}
+
+		result[name] = d.Summary()
+	}
+
+	return result
+}
+
+// WriteComparison writes a table comparing the given summaries, by name, to w.
+func WriteComparison(w io.Writer, summaries map[string]DiagnosticsSummary) error {
+	names := make([]string, 0, len(summaries))
+	for name := range summaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "sampler\titers\tinfosets\tnodes/iter\ttime/iter\tcfv var\tregret var\tregret stddev\t")
+	for _, name := range names {
+		s := summaries[name]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%v\t%.4g\t%.4g\t%.4g\t\n",
+			name, s.Iterations, s.NumInfoSets, s.MeanNodesTouched, s.MeanWallTime,
+			s.MeanCFValueVariance, s.MeanRegretVariance, math.Sqrt(s.MeanRegretVariance))
+	}
+
+	return tw.Flush()
+}
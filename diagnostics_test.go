@@ -0,0 +1,64 @@
+package cfr_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+	"github.com/tam0705/go-cfr/holdem"
+	"github.com/tam0705/go-cfr/sampling"
+)
+
+func TestRunningStat(t *testing.T) {
+	xs := []float64{1, 4, 2, 8, 5, 7}
+	var all, a, b cfr.RunningStat
+	for i, x := range xs {
+		all.Add(x)
+		if i < 2 {
+			a.Add(x)
+		} else {
+			b.Add(x)
+		}
+	}
+
+	// Direct computation: mean = 4.5, sum of squared deviations = 37.5.
+	if all.Mean != 4.5 || math.Abs(all.Variance()-37.5/5) > 1e-9 {
+		t.Errorf("expected mean 4.5 and variance 7.5, got %v and %v", all.Mean, all.Variance())
+	}
+
+	a.Merge(b)
+	if a.N != all.N || math.Abs(a.Mean-all.Mean) > 1e-9 || math.Abs(a.Variance()-all.Variance()) > 1e-9 {
+		t.Errorf("merged stats %+v differ from %+v", a, all)
+	}
+}
+
+func TestCompareSamplers(t *testing.T) {
+	newProfile := func() cfr.StrategyProfile {
+		return cfr.NewPolicyTable(cfr.DiscountParams{})
+	}
+
+	root := holdem.NewGame(cfr.NewPolicyTable(cfr.DiscountParams{}))
+	samplers := map[string]cfr.Sampler{
+		"outcome": sampling.NewOutcomeSampler(0.1),
+		"robust":  sampling.NewRobustSampler(2),
+	}
+
+	summaries := cfr.CompareSamplers(root, newProfile, samplers, 100)
+	for name, s := range summaries {
+		if s.Iterations != 100 || s.NumInfoSets == 0 || s.MeanNodesTouched == 0 {
+			t.Errorf("%s: unexpected summary: %+v", name, s)
+		}
+	}
+
+	if summaries["robust"].MeanNodesTouched <= summaries["outcome"].MeanNodesTouched {
+		t.Errorf("expected robust sampling to touch more nodes than outcome sampling")
+	}
+
+	var buf bytes.Buffer
+	if err := cfr.WriteComparison(&buf, summaries); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log("\n" + buf.String())
+}
@@ -0,0 +1,221 @@
+// Package efg loads extensive-form games from Gambit .efg files.
+//
+// The file format is described in the Gambit documentation:
+// https://gambitproject.readthedocs.io/en/latest/formats.html
+//
+// A parsed game is held in memory as a tree of EFGNodes, which implement
+// cfr.GameTreeNode. Players are numbered from 0, so Gambit's player 1 is
+// player 0 here. Chance nodes take their probabilities from the file, and
+// the InfoSets of player nodes are given by the file's infoset numbers.
+package efg
+
+import (
+	"encoding/gob"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tam0705/go-cfr"
+	"github.com/tam0705/go-cfr/sampling"
+)
+
+const NODE_CHANCE = -1
+
+// Game is an extensive-form game loaded from a .efg file.
+type Game struct {
+	// Title is the game's title.
+	Title string
+	// Players are the names of the players.
+	Players []string
+	// Comment is the game's optional comment.
+	Comment string
+
+	root *EFGNode
+}
+
+// NumPlayers returns the number of players in the game.
+func (g *Game) NumPlayers() int {
+	return len(g.Players)
+}
+
+// Root returns the root of the game tree.
+func (g *Game) Root() *EFGNode {
+	return g.root
+}
+
+// EFGNode implements cfr.GameTreeNode for a game loaded from a .efg file.
+//
+// The whole tree is held in memory, so Close does not release its children.
+type EFGNode struct {
+	parent        *EFGNode
+	nodeType      cfr.NodeType
+	name          string
+	player        int
+	infoSet       InfoSet
+	actions       []string
+	children      []*EFGNode
+	probabilities []float64
+	// utility is the sum of the payoffs of the outcomes on the path
+	// from the root to this terminal node.
+	utility []float64
+}
+
+// String implements fmt.Stringer.
+func (k *EFGNode) String() string {
+	switch k.nodeType {
+	case cfr.TerminalNodeType:
+		return fmt.Sprintf("Terminal node %q. Utility: %v", k.name, k.utility)
+	case cfr.ChanceNodeType:
+		return fmt.Sprintf("Chance node %q. Actions: %v", k.name, k.actions)
+	default:
+		return fmt.Sprintf("Player %v's turn at node %q. InfoSet: %d Actions: %v",
+			k.player, k.name, k.infoSet.Number, k.actions)
+	}
+}
+
+// Name returns the node's name in the .efg file, which may be empty.
+func (k *EFGNode) Name() string {
+	return k.name
+}
+
+// Actions returns the labels of the actions leading to each child.
+func (k *EFGNode) Actions() []string {
+	return k.actions
+}
+
+// GetNode implements cfr.GameTreeNode. It returns the node reached by
+// following the given space-separated action labels from this node,
+// or nil if there is no such node.
+func (k *EFGNode) GetNode(history string) cfr.GameTreeNode {
+	node := k
+	for _, action := range strings.Fields(history) {
+		next := -1
+		for i, a := range node.actions {
+			if a == action {
+				next = i
+				break
+			}
+		}
+
+		if next < 0 {
+			return nil
+		}
+
+		node = node.children[next]
+	}
+
+	return node
+}
+
+// Close implements cfr.GameTreeNode. It does nothing, since the tree is
+// held in memory.
+func (k *EFGNode) Close() {}
+
+// Type implements cfr.GameTreeNode.
+func (k *EFGNode) Type() cfr.NodeType {
+	return k.nodeType
+}
+
+// NumChildren implements cfr.GameTreeNode.
+func (k *EFGNode) NumChildren() int {
+	return len(k.children)
+}
+
+// GetChild implements cfr.GameTreeNode.
+func (k *EFGNode) GetChild(i int) cfr.GameTreeNode {
+	return k.children[i]
+}
+
+// Parent implements cfr.GameTreeNode.
+func (k *EFGNode) Parent() cfr.GameTreeNode {
+	if k.parent == nil {
+		return nil
+	}
+
+	return k.parent
+}
+
+// GetChildProbability implements cfr.GameTreeNode.
+func (k *EFGNode) GetChildProbability(i int) float64 {
+	if k.probabilities == nil {
+		return 0.0
+	}
+
+	return k.probabilities[i]
+}
+
+// SampleChild implements cfr.GameTreeNode.
+func (k *EFGNode) SampleChild() (cfr.GameTreeNode, float64) {
+	return sampling.SampleChanceNode(k)
+}
+
+// Player implements cfr.GameTreeNode.
+func (k *EFGNode) Player() int {
+	return k.player
+}
+
+// Utility implements cfr.GameTreeNode.
+func (k *EFGNode) Utility(player int) float64 {
+	return k.utility[player]
+}
+
+// InfoSet implements cfr.GameTreeNode. Only the acting player's
+// InfoSet is defined by the file, so it is returned for any player.
+func (k *EFGNode) InfoSet(player int) cfr.InfoSet {
+	is := k.infoSet
+	return &is
+}
+
+// InfoSetKey implements cfr.GameTreeNode.
+func (k *EFGNode) InfoSetKey(player int) []byte {
+	return k.infoSet.Key()
+}
+
+// LegalActions implements cfr.GameTreeNode. All actions listed
+// in the file are legal.
+func (k *EFGNode) LegalActions() []bool {
+	return nil
+}
+
+// InfoSet is an information set of a player, identified
+// by its number in the .efg file.
+type InfoSet struct {
+	Player int
+	Number int
+}
+
+// Key implements cfr.InfoSet.
+func (is *InfoSet) Key() []byte {
+	return []byte(strconv.Itoa(is.Player) + ":" + strconv.Itoa(is.Number))
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (is *InfoSet) MarshalBinary() ([]byte, error) {
+	return is.Key(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (is *InfoSet) UnmarshalBinary(buf []byte) error {
+	fields := strings.Split(string(buf), ":")
+	if len(fields) != 2 {
+		return fmt.Errorf("efg: invalid InfoSet: %q", buf)
+	}
+
+	player, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return fmt.Errorf("efg: invalid InfoSet player: %v", err)
+	}
+
+	number, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return fmt.Errorf("efg: invalid InfoSet number: %v", err)
+	}
+
+	is.Player = player
+	is.Number = number
+	return nil
+}
+
+func init() {
+	gob.Register(&InfoSet{})
+}
@@ -0,0 +1,214 @@
+package efg
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+	"github.com/tam0705/go-cfr/internal/cfrtest"
+	"github.com/tam0705/go-cfr/kuhn"
+	"github.com/tam0705/go-cfr/sampling"
+	"github.com/tam0705/go-cfr/tree"
+)
+
+func TestParseKuhn(t *testing.T) {
+	game, err := ParseFile("testdata/kuhn.efg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if game.Title != "Kuhn poker" || game.NumPlayers() != 2 || game.Players[1] != "Player 2" {
+		t.Errorf("unexpected header: %+v", game)
+	}
+	if !strings.HasPrefix(game.Comment, "Each player antes 1") {
+		t.Errorf("unexpected comment: %q", game.Comment)
+	}
+
+	root := game.Root()
+	if n := tree.CountNodes(root); n != 55 {
+		t.Errorf("expected 55 nodes, got %d", n)
+	}
+	if n := tree.CountTerminalNodes(root); n != 30 {
+		t.Errorf("expected 30 terminal nodes, got %d", n)
+	}
+	if n := tree.CountInfoSets(root); n != 12 {
+		t.Errorf("expected 12 infosets, got %d", n)
+	}
+	if problems := tree.Validate(root); len(problems) > 0 {
+		t.Errorf("invalid game tree: %v", problems)
+	}
+
+	for _, tc := range []struct {
+		history string
+		want    float64
+	}{
+		{"JQ p p", -1},
+		{"QK p b p", -1},
+		{"KJ b b", 2},
+		{"QJ b p", 1},
+	} {
+		node := root.GetNode(tc.history)
+		if node == nil || node.Type() != cfr.TerminalNodeType {
+			t.Errorf("%s: expected terminal node, got %v", tc.history, node)
+			continue
+		}
+
+		if u := node.Utility(0); u != tc.want {
+			t.Errorf("%s: expected utility %v, got %v", tc.history, tc.want, u)
+		}
+	}
+
+	if node := root.GetNode("JQ x"); node != nil {
+		t.Errorf("expected nil for an invalid action, got %v", node)
+	}
+}
+
+const threePlayerGame = `EFG 2 D "Three \"player\" game" { "A" "B" "C" }
+
+c "root" 1 "" { "left" 0.25 "right" 3/4 } 1 "entry" { 1 0 -1 }
+p "" 1 1 "A's infoset" { "x" "y" } 0
+t "" 2 "win" { 10 -5 -5 }
+p "" 3 1 "" { "u" "v" "w" } 3 "" { 0, 0, 2.5 }
+t "" 0
+t "" 2
+t "" 4 "" { -1e1 0 10 }
+p "" 1 1 0
+t "" 2
+t "" 2
+`
+
+func TestParseOutcomes(t *testing.T) {
+	game, err := Parse(strings.NewReader(threePlayerGame))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if game.Title != `Three "player" game` || game.NumPlayers() != 3 || game.Comment != "" {
+		t.Errorf("unexpected header: %+v", game)
+	}
+
+	root := game.Root()
+	if p := root.GetChildProbability(1); p != 0.75 {
+		t.Errorf("expected probability 0.75, got %v", p)
+	}
+	if root.Name() != "root" || root.Actions()[0] != "left" {
+		t.Errorf("unexpected root: %v", root)
+	}
+
+	// Outcomes at non-terminal nodes add to the payoffs of the terminal
+	// nodes below them.
+	for _, tc := range []struct {
+		history string
+		want    []float64
+	}{
+		{"left x", []float64{11, -5, -6}},
+		{"left y u", []float64{1, 0, 1.5}},
+		{"left y v", []float64{11, -5, -3.5}},
+		{"left y w", []float64{-9, 0, 11.5}},
+		{"right y", []float64{11, -5, -6}},
+	} {
+		node := root.GetNode(tc.history)
+		if node == nil {
+			t.Errorf("%s: node not found", tc.history)
+			continue
+		}
+
+		for player, want := range tc.want {
+			if u := node.Utility(player); u != want {
+				t.Errorf("%s: expected utility %v for player %d, got %v", tc.history, want, player, u)
+			}
+		}
+	}
+
+	// Both of player A's nodes are in the same infoset, which
+	// is distinct from player C's infoset 1.
+	a1, a2 := root.GetNode("left"), root.GetNode("right")
+	if string(a1.InfoSetKey(0)) != string(a2.InfoSetKey(0)) {
+		t.Error("expected player A's nodes to be in the same infoset")
+	}
+	if c := root.GetNode("left y"); c.Player() != 2 || string(c.InfoSetKey(2)) == string(a1.InfoSetKey(0)) {
+		t.Errorf("unexpected infoset for player C: %v", c)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		err   string
+	}{
+		{`NFG 1 R "" { "A" }`, "expected EFG"},
+		{`EFG 2 R "" { }`, "no players"},
+		{`EFG 2 R "" { "A" } t "" 1`, "line 1: outcome 1 has no payoffs"},
+		{`EFG 2 R "" { "A" } t "" 1 "" { 1 2 }`, "has 2 payoffs for 1 players"},
+		{`EFG 2 R "" { "A" } p "" 2 1 "" { "x" } 0 t "" 0`, "invalid player number"},
+		{`EFG 2 R "" { "A" } p "" 1 1 0`, "has no actions"},
+		{"EFG 2 R \"\" { \"A\" }\nc \"\" 1 \"\" { \"x\" 1/2 \"y\" 1/3 } 0", "line 2: chance probabilities sum to"},
+		{`EFG 2 R "" { "A" } c "" 1 "" { "x" a } 0`, "invalid probability"},
+		{`EFG 2 R "" { "A" } p "" 1 1 "" { "x" } 0`, "expected node type, got end of file"},
+		{`EFG 2 R "" { "A" } p "" 1 1 "" { "x" } 0 p "" 1 1 "" { "x" "y" } 0 t "" 0 t "" 0`, "has 2 actions, previously 1"},
+		{`EFG 2 R "" { "A" } t "" 0 t "" 0`, "after the game tree"},
+		{`EFG 2 R "unterminated`, "unterminated string"},
+	} {
+		_, err := Parse(strings.NewReader(tc.input))
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.input, tc.err, err)
+		}
+	}
+}
+
+func TestInfoSetMarshal(t *testing.T) {
+	is := &InfoSet{Player: 1, Number: 12}
+	buf, err := is.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded InfoSet
+	if err := decoded.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded != *is {
+		t.Errorf("expected %+v, got %+v", is, decoded)
+	}
+
+	if err := decoded.UnmarshalBinary([]byte("1")); err == nil {
+		t.Error("expected error for invalid InfoSet")
+	}
+}
+
+func TestCompiledCFR(t *testing.T) {
+	game, err := ParseFile("testdata/kuhn.efg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compiled, err := tree.Compile(context.Background(), game.Root(), game.NumPlayers())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := tree.NewCompiledCFR(compiled, cfr.DiscountParams{LinearWeighting: true})
+	for i := 0; i < 2000; i++ {
+		c.Run()
+	}
+
+	ev := tree.ExpectedValue(game.Root(), c.GetNodeAverageStrategy)
+
+	if math.Abs(ev-kuhn.GAME_VALUE) > 0.01 {
+		t.Errorf("expected game value %.4f, got %.4f", kuhn.GAME_VALUE, ev)
+	}
+}
+
+func TestMCCFR(t *testing.T) {
+	game, err := ParseFile("testdata/kuhn.efg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profile := cfr.NewPolicyTable(cfr.DiscountParams{LinearWeighting: true})
+	cfrtest.Train(profile, game.Root(), sampling.NewExternalSampler(), 20000)
+	cfrtest.CheckGameValue(t, "MCCFR", game.Root(), profile, kuhn.GAME_VALUE, 0.01)
+}
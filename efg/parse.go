@@ -0,0 +1,559 @@
+package efg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// probabilityTolerance is the allowed error in the sum of
+// a chance node's probabilities.
+const probabilityTolerance = 1e-6
+
+// ParseFile parses the .efg file with the given name.
+func ParseFile(filename string) (*Game, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse parses a game in the .efg format from r.
+func Parse(r io.Reader) (*Game, error) {
+	p := &parser{
+		s:        &scanner{r: bufio.NewReader(r), line: 1},
+		infoSets: make(map[InfoSet]*infoSetDef),
+		outcomes: make(map[int][]float64),
+	}
+
+	if err := p.parseHeader(); err != nil {
+		return nil, err
+	}
+
+	root, err := p.parseNode(nil, make([]float64, p.game.NumPlayers()))
+	if err != nil {
+		return nil, err
+	}
+
+	if tok, err := p.s.next(); err != nil {
+		return nil, err
+	} else if tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %v after the game tree", tok)
+	}
+
+	p.game.root = root
+	return p.game, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenString
+	tokenWord
+	tokenLBrace
+	tokenRBrace
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// String implements fmt.Stringer.
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of file"
+	case tokenString:
+		return strconv.Quote(t.text)
+	case tokenLBrace:
+		return "'{'"
+	case tokenRBrace:
+		return "'}'"
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// scanner splits a .efg file into tokens. Commas are
+// treated as whitespace.
+type scanner struct {
+	r      *bufio.Reader
+	line   int
+	peeked *token
+}
+
+func isSpace(c rune) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == ','
+}
+
+// peek returns the next token without consuming it.
+func (s *scanner) peek() (token, error) {
+	if s.peeked == nil {
+		tok, err := s.scan()
+		if err != nil {
+			return tok, err
+		}
+		s.peeked = &tok
+	}
+
+	return *s.peeked, nil
+}
+
+// next consumes and returns the next token.
+func (s *scanner) next() (token, error) {
+	tok, err := s.peek()
+	s.peeked = nil
+	return tok, err
+}
+
+func (s *scanner) scan() (token, error) {
+	c, err := s.skipSpace()
+	if err == io.EOF {
+		return token{kind: tokenEOF, line: s.line}, nil
+	} else if err != nil {
+		return token{}, err
+	}
+
+	switch c {
+	case '{':
+		return token{kind: tokenLBrace, line: s.line}, nil
+	case '}':
+		return token{kind: tokenRBrace, line: s.line}, nil
+	case '"':
+		return s.scanString()
+	}
+
+	tok := token{kind: tokenWord, line: s.line}
+	var sb strings.Builder
+	sb.WriteRune(c)
+	for {
+		c, _, err := s.r.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return token{}, err
+		}
+
+		if isSpace(c) || c == '{' || c == '}' || c == '"' {
+			s.r.UnreadRune()
+			break
+		}
+
+		sb.WriteRune(c)
+	}
+
+	tok.text = sb.String()
+	return tok, nil
+}
+
+// skipSpace returns the first rune that is not whitespace.
+func (s *scanner) skipSpace() (rune, error) {
+	for {
+		c, _, err := s.r.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+
+		if c == '\n' {
+			s.line++
+		}
+
+		if !isSpace(c) {
+			return c, nil
+		}
+	}
+}
+
+// scanString scans a quoted string, after its opening quote.
+// A backslash escapes the following character.
+func (s *scanner) scanString() (token, error) {
+	tok := token{kind: tokenString, line: s.line}
+	var sb strings.Builder
+	escaped := false
+	for {
+		c, _, err := s.r.ReadRune()
+		if err == io.EOF {
+			return token{}, fmt.Errorf("efg: line %d: unterminated string", tok.line)
+		} else if err != nil {
+			return token{}, err
+		}
+
+		if c == '\n' {
+			s.line++
+		}
+
+		switch {
+		case escaped:
+			sb.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			tok.text = sb.String()
+			return tok, nil
+		default:
+			sb.WriteRune(c)
+		}
+	}
+}
+
+// infoSetDef holds the actions of an infoset, and for
+// chance nodes their probabilities.
+type infoSetDef struct {
+	actions       []string
+	probabilities []float64
+}
+
+type parser struct {
+	s        *scanner
+	game     *Game
+	infoSets map[InfoSet]*infoSetDef
+	outcomes map[int][]float64
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return fmt.Errorf("efg: line %d: %s", tok.line, fmt.Sprintf(format, args...))
+}
+
+// expect consumes the next token, which must be of the given kind.
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	tok, err := p.s.next()
+	if err != nil {
+		return tok, err
+	}
+
+	if tok.kind != kind {
+		return tok, p.errorf(tok, "expected %s, got %v", what, tok)
+	}
+
+	return tok, nil
+}
+
+// accept consumes the next token if it is of the given kind.
+func (p *parser) accept(kind tokenKind) (token, bool, error) {
+	tok, err := p.s.peek()
+	if err != nil || tok.kind != kind {
+		return tok, false, err
+	}
+
+	tok, err = p.s.next()
+	return tok, err == nil, err
+}
+
+func (p *parser) parseInt(what string) (int, error) {
+	tok, err := p.expect(tokenWord, what)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.Atoi(tok.text)
+	if err != nil || n < 0 {
+		return 0, p.errorf(tok, "invalid %s: %v", what, tok)
+	}
+
+	return n, nil
+}
+
+// parseNumber parses a decimal or rational number, such as 0.25 or 1/3.
+func (p *parser) parseNumber(what string) (float64, error) {
+	tok, err := p.expect(tokenWord, what)
+	if err != nil {
+		return 0, err
+	}
+
+	r, ok := new(big.Rat).SetString(tok.text)
+	if !ok {
+		return 0, p.errorf(tok, "invalid %s: %v", what, tok)
+	}
+
+	x, _ := r.Float64()
+	return x, nil
+}
+
+// parseStrings parses a brace-delimited list of strings.
+func (p *parser) parseStrings(what string) ([]string, error) {
+	if _, err := p.expect(tokenLBrace, "'{'"); err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for {
+		if _, ok, err := p.accept(tokenRBrace); err != nil {
+			return nil, err
+		} else if ok {
+			return result, nil
+		}
+
+		tok, err := p.expect(tokenString, what)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, tok.text)
+	}
+}
+
+// parseHeader parses the file format, title, players and comment.
+func (p *parser) parseHeader() error {
+	tok, err := p.expect(tokenWord, "EFG")
+	if err != nil {
+		return err
+	} else if tok.text != "EFG" {
+		return p.errorf(tok, "expected EFG, got %v", tok)
+	}
+
+	tok, err = p.expect(tokenWord, "version")
+	if err != nil {
+		return err
+	} else if tok.text != "2" {
+		return p.errorf(tok, "unsupported version: %v", tok)
+	}
+
+	tok, err = p.expect(tokenWord, "number format")
+	if err != nil {
+		return err
+	} else if tok.text != "R" && tok.text != "D" {
+		return p.errorf(tok, "unsupported number format: %v", tok)
+	}
+
+	title, err := p.expect(tokenString, "title")
+	if err != nil {
+		return err
+	}
+
+	players, err := p.parseStrings("player name")
+	if err != nil {
+		return err
+	} else if len(players) == 0 {
+		return p.errorf(title, "game has no players")
+	}
+
+	p.game = &Game{Title: title.text, Players: players}
+	if tok, ok, err := p.accept(tokenString); err != nil {
+		return err
+	} else if ok {
+		p.game.Comment = tok.text
+	}
+
+	return nil
+}
+
+// parseNode parses the node at the start of the remaining input and its
+// subtree. The utility of terminal nodes is accumulated from the payoffs
+// of the outcomes on the path to them, which utility holds.
+func (p *parser) parseNode(parent *EFGNode, utility []float64) (*EFGNode, error) {
+	tok, err := p.expect(tokenWord, "node type")
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := p.expect(tokenString, "node name")
+	if err != nil {
+		return nil, err
+	}
+
+	node := &EFGNode{parent: parent, name: name.text}
+	switch tok.text {
+	case "t":
+		node.nodeType = cfr.TerminalNodeType
+		node.player = NODE_CHANCE
+		node.utility, err = p.parseOutcome(utility)
+		return node, err
+	case "c":
+		node.nodeType = cfr.ChanceNodeType
+		node.player = NODE_CHANCE
+	case "p":
+		node.nodeType = cfr.PlayerNodeType
+		player, err := p.parseInt("player number")
+		if err != nil {
+			return nil, err
+		} else if player < 1 || player > p.game.NumPlayers() {
+			return nil, p.errorf(name, "invalid player number: %d", player)
+		}
+
+		node.player = player - 1
+	default:
+		return nil, p.errorf(tok, "invalid node type: %v", tok)
+	}
+
+	if err := p.parseInfoSet(node); err != nil {
+		return nil, err
+	}
+
+	if utility, err = p.parseOutcome(utility); err != nil {
+		return nil, err
+	}
+
+	node.children = make([]*EFGNode, len(node.actions))
+	for i := range node.children {
+		if node.children[i], err = p.parseNode(node, utility); err != nil {
+			return nil, err
+		}
+	}
+
+	return node, nil
+}
+
+// parseInfoSet parses the infoset of a chance or player node. Its name
+// and actions are optional if the infoset has appeared before.
+func (p *parser) parseInfoSet(node *EFGNode) error {
+	number, err := p.parseInt("infoset number")
+	if err != nil {
+		return err
+	}
+
+	node.infoSet = InfoSet{Player: node.player, Number: number}
+	tok, err := p.s.peek()
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := p.accept(tokenString); err != nil {
+		return err
+	}
+
+	def, err := p.parseActions(node.nodeType == cfr.ChanceNodeType)
+	if err != nil {
+		return err
+	}
+
+	prev := p.infoSets[node.infoSet]
+	switch {
+	case def == nil && prev == nil:
+		return p.errorf(tok, "infoset %d of player %d has no actions", number, node.player+1)
+	case def == nil:
+		def = prev
+	case prev == nil:
+		p.infoSets[node.infoSet] = def
+	case len(def.actions) != len(prev.actions):
+		return p.errorf(tok, "infoset %d of player %d has %d actions, previously %d",
+			number, node.player+1, len(def.actions), len(prev.actions))
+	}
+
+	if len(def.actions) == 0 {
+		return p.errorf(tok, "infoset %d of player %d has no actions", number, node.player+1)
+	}
+
+	node.actions = def.actions
+	node.probabilities = def.probabilities
+	return nil
+}
+
+// parseActions parses an optional list of actions, which for chance
+// nodes are followed by their probabilities. It returns nil if
+// there is no list.
+func (p *parser) parseActions(chance bool) (*infoSetDef, error) {
+	start, ok, err := p.accept(tokenLBrace)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	def := &infoSetDef{}
+	for {
+		if _, ok, err := p.accept(tokenRBrace); err != nil {
+			return nil, err
+		} else if ok {
+			break
+		}
+
+		action, err := p.expect(tokenString, "action name")
+		if err != nil {
+			return nil, err
+		}
+
+		def.actions = append(def.actions, action.text)
+		if chance {
+			prob, err := p.parseNumber("probability")
+			if err != nil {
+				return nil, err
+			} else if prob < 0 {
+				return nil, p.errorf(action, "negative probability for action %v", action)
+			}
+
+			def.probabilities = append(def.probabilities, prob)
+		}
+	}
+
+	if chance {
+		var total float64
+		for _, prob := range def.probabilities {
+			total += prob
+		}
+
+		if math.Abs(total-1) > probabilityTolerance {
+			return nil, p.errorf(start, "chance probabilities sum to %v", total)
+		}
+	}
+
+	return def, nil
+}
+
+// parseOutcome parses an outcome, and returns the given utility plus its
+// payoffs. Its name and payoffs are optional if the outcome has appeared
+// before, and outcome 0 is the null outcome with no payoffs.
+func (p *parser) parseOutcome(utility []float64) ([]float64, error) {
+	tok, err := p.s.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	number, err := p.parseInt("outcome number")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, err := p.accept(tokenString); err != nil {
+		return nil, err
+	}
+
+	payoffs, defined := p.outcomes[number]
+	if _, ok, err := p.accept(tokenLBrace); err != nil {
+		return nil, err
+	} else if ok {
+		payoffs = nil
+		for {
+			if _, ok, err := p.accept(tokenRBrace); err != nil {
+				return nil, err
+			} else if ok {
+				break
+			}
+
+			x, err := p.parseNumber("payoff")
+			if err != nil {
+				return nil, err
+			}
+
+			payoffs = append(payoffs, x)
+		}
+
+		if len(payoffs) != p.game.NumPlayers() {
+			return nil, p.errorf(tok, "outcome %d has %d payoffs for %d players",
+				number, len(payoffs), p.game.NumPlayers())
+		}
+
+		p.outcomes[number] = payoffs
+	} else if !defined && number != 0 {
+		return nil, p.errorf(tok, "outcome %d has no payoffs", number)
+	}
+
+	result := make([]float64, len(utility))
+	copy(result, utility)
+	for i, x := range payoffs {
+		result[i] += x
+	}
+
+	return result, nil
+}
@@ -0,0 +1,349 @@
+// Package goofspiel implements two-player Goofspiel, a bidding game with
+// simultaneous moves.
+//
+// Each player holds the cards 1..N, and there is a deck of point cards 1..N.
+// In each turn a point card is revealed, and both players secretly bid one
+// of their remaining cards for it. The higher bid wins the point card, and
+// on a tie it is discarded. Both bids are revealed after each turn.
+//
+// The simultaneous bids are modeled as sequential moves: player 0 bids
+// first, and player 1 bids without observing player 0's bid, which is hidden
+// from player 1's InfoSet until the end of the turn.
+package goofspiel
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math/rand"
+
+	"github.com/tam0705/go-cfr"
+)
+
+const (
+	NODE_CHANCE = -1
+	NODE_P0     = 0
+	NODE_P1     = 1
+)
+
+// MAX_CARDS is the largest supported number of cards per player.
+const MAX_CARDS = 32
+
+// Params configures a game of Goofspiel.
+type Params struct {
+	// Cards is the number of cards in each player's hand and the point deck.
+	Cards int
+	// PointOrder is the order in which the point cards are revealed,
+	// a permutation of 1..Cards such as Ascending(n). If it is nil, the
+	// point cards are shuffled and each one is revealed by a chance node.
+	PointOrder []int
+}
+
+// Ascending returns the point card order 1, 2, ..., n.
+func Ascending(n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i + 1
+	}
+
+	return order
+}
+
+// Descending returns the point card order n, n-1, ..., 1.
+func Descending(n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = n - i
+	}
+
+	return order
+}
+
+// GoofspielNode implements cfr.GameTreeNode for Goofspiel.
+type GoofspielNode struct {
+	parent        *GoofspielNode
+	params        *Params
+	player        int
+	children      []GoofspielNode
+	probabilities []float64
+
+	// history has three bytes per turn: the point card, player 0's bid
+	// and player 1's bid.
+	history string
+	// Bitmasks of the cards remaining in each player's hand,
+	// and in the point deck.
+	hands  [2]uint32
+	points uint32
+	score  [2]int
+}
+
+// NewGame returns the root of a new Goofspiel game tree.
+func NewGame(params Params) *GoofspielNode {
+	if params.Cards < 1 || params.Cards > MAX_CARDS {
+		panic(fmt.Errorf("goofspiel: invalid number of cards: %d", params.Cards))
+	}
+	if params.PointOrder != nil && len(params.PointOrder) != params.Cards {
+		panic(fmt.Errorf("goofspiel: point order %v does not have %d cards",
+			params.PointOrder, params.Cards))
+	}
+	var seen uint32
+	for _, c := range params.PointOrder {
+		if c < 1 || c > params.Cards || seen&(1<<uint(c-1)) != 0 {
+			panic(fmt.Errorf("goofspiel: point order %v is not a permutation of 1..%d",
+				params.PointOrder, params.Cards))
+		}
+		seen |= 1 << uint(c-1)
+	}
+
+	all := uint32(1<<uint(params.Cards)) - 1
+	if params.Cards == MAX_CARDS {
+		all = ^uint32(0)
+	}
+
+	root := &GoofspielNode{
+		params: &params,
+		hands:  [2]uint32{all, all},
+		points: all,
+	}
+	root.startTurn()
+	return root
+}
+
+// startTurn reveals the next point card, or ends the game.
+func (k *GoofspielNode) startTurn() {
+	turn := len(k.history) / 3
+	switch {
+	case turn == k.params.Cards:
+		k.player = NODE_CHANCE // Unused, the node is terminal.
+	case k.params.PointOrder == nil:
+		k.player = NODE_CHANCE
+	default:
+		k.reveal(k.params.PointOrder[turn])
+	}
+}
+
+// reveal reveals the given point card, and starts the bidding.
+func (k *GoofspielNode) reveal(card int) {
+	k.history += string([]byte{byte(card)})
+	k.points &^= 1 << uint(card-1)
+	k.player = NODE_P0
+}
+
+// String implements fmt.Stringer.
+func (k GoofspielNode) String() string {
+	return fmt.Sprintf("Player %v's turn. History: %v Score: %v",
+		k.player, []byte(k.history), k.score)
+}
+
+// GetNode implements cfr.GameTreeNode. Goofspiel nodes are not addressable
+// by a history string, so it always returns nil.
+func (k *GoofspielNode) GetNode(history string) cfr.GameTreeNode {
+	return nil
+}
+
+// Close implements cfr.GameTreeNode.
+func (k *GoofspielNode) Close() {
+	k.children = nil
+	k.probabilities = nil
+}
+
+// Type implements cfr.GameTreeNode.
+func (k *GoofspielNode) Type() cfr.NodeType {
+	if k.IsTerminal() {
+		return cfr.TerminalNodeType
+	} else if k.player == NODE_CHANCE {
+		return cfr.ChanceNodeType
+	}
+
+	return cfr.PlayerNodeType
+}
+
+// IsTerminal returns whether all turns have been played.
+func (k *GoofspielNode) IsTerminal() bool {
+	return len(k.history) == 3*k.params.Cards
+}
+
+// NumChildren implements cfr.GameTreeNode.
+func (k *GoofspielNode) NumChildren() int {
+	if k.children == nil {
+		k.buildChildren()
+	}
+
+	return len(k.children)
+}
+
+// GetChild implements cfr.GameTreeNode.
+func (k *GoofspielNode) GetChild(i int) cfr.GameTreeNode {
+	if k.children == nil {
+		k.buildChildren()
+	}
+
+	return &k.children[i]
+}
+
+// Parent implements cfr.GameTreeNode.
+func (k *GoofspielNode) Parent() cfr.GameTreeNode {
+	if k.parent == nil {
+		return nil
+	}
+
+	return k.parent
+}
+
+// GetChildProbability implements cfr.GameTreeNode.
+func (k *GoofspielNode) GetChildProbability(i int) float64 {
+	if k.children == nil {
+		k.buildChildren()
+	}
+	if k.probabilities == nil {
+		return 0.0
+	}
+
+	return k.probabilities[i]
+}
+
+// SampleChild implements cfr.GameTreeNode. All point cards are equally likely.
+func (k *GoofspielNode) SampleChild() (cfr.GameTreeNode, float64) {
+	i := rand.Intn(k.NumChildren())
+	return k.GetChild(i), k.GetChildProbability(i)
+}
+
+// Player implements cfr.GameTreeNode.
+func (k *GoofspielNode) Player() int {
+	return k.player
+}
+
+// Utility implements cfr.GameTreeNode. It is the difference between
+// the points won by the player and by their opponent.
+func (k *GoofspielNode) Utility(player int) float64 {
+	return float64(k.score[player] - k.score[1-player])
+}
+
+// InfoSet implements cfr.GameTreeNode.
+func (k *GoofspielNode) InfoSet(player int) cfr.InfoSet {
+	history := k.history
+	if player == NODE_P1 && len(history)%3 == 2 {
+		// Player 0's bid in the current turn is hidden from player 1.
+		history = history[:len(history)-1]
+	}
+
+	return &InfoSet{
+		Player:  player,
+		History: history,
+	}
+}
+
+// InfoSetKey implements cfr.GameTreeNode.
+func (k *GoofspielNode) InfoSetKey(player int) []byte {
+	return k.InfoSet(player).Key()
+}
+
+// LegalActions implements cfr.GameTreeNode. Only the cards remaining in
+// a player's hand have a child node, so all children are legal.
+func (k *GoofspielNode) LegalActions() []bool {
+	return nil
+}
+
+// cards returns the cards in the given bitmask, in ascending order.
+func cards(mask uint32) []int {
+	var result []int
+	for c := 1; mask != 0; c++ {
+		if mask&1 != 0 {
+			result = append(result, c)
+		}
+		mask >>= 1
+	}
+
+	return result
+}
+
+func (k *GoofspielNode) buildChildren() {
+	if k.IsTerminal() {
+		return
+	}
+
+	if k.player == NODE_CHANCE {
+		remaining := cards(k.points)
+		k.children = make([]GoofspielNode, len(remaining))
+		k.probabilities = make([]float64, len(remaining))
+		for i, card := range remaining {
+			child := k.child()
+			child.reveal(card)
+			k.children[i] = child
+			k.probabilities[i] = 1.0 / float64(len(remaining))
+		}
+
+		return
+	}
+
+	hand := cards(k.hands[k.player])
+	k.children = make([]GoofspielNode, len(hand))
+	for i, card := range hand {
+		child := k.child()
+		child.history += string([]byte{byte(card)})
+		child.hands[k.player] &^= 1 << uint(card-1)
+		if k.player == NODE_P0 {
+			child.player = NODE_P1
+		} else {
+			child.endTurn()
+		}
+
+		k.children[i] = child
+	}
+}
+
+// child returns a copy of this node's state, as its child.
+func (k *GoofspielNode) child() GoofspielNode {
+	child := *k
+	child.parent = k
+	child.children = nil
+	child.probabilities = nil
+	return child
+}
+
+// endTurn awards the point card to the higher bid, and starts the next turn.
+func (k *GoofspielNode) endTurn() {
+	n := len(k.history)
+	point, bid0, bid1 := k.history[n-3], k.history[n-2], k.history[n-1]
+	if bid0 > bid1 {
+		k.score[0] += int(point)
+	} else if bid1 > bid0 {
+		k.score[1] += int(point)
+	}
+
+	k.startTurn()
+}
+
+// InfoSet is the information available to a player: the point cards and
+// bids revealed so far, and their own bid in the current turn.
+type InfoSet struct {
+	Player  int
+	History string
+}
+
+// Key implements cfr.InfoSet.
+func (is *InfoSet) Key() []byte {
+	// Both players may see the same history, so the key includes the player.
+	key := make([]byte, 0, 1+len(is.History))
+	key = append(key, byte(is.Player))
+	return append(key, is.History...)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (is *InfoSet) MarshalBinary() ([]byte, error) {
+	return is.Key(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (is *InfoSet) UnmarshalBinary(buf []byte) error {
+	if len(buf) == 0 {
+		return fmt.Errorf("goofspiel: empty InfoSet")
+	}
+
+	is.Player = int(buf[0])
+	is.History = string(buf[1:])
+	return nil
+}
+
+func init() {
+	gob.Register(&InfoSet{})
+}
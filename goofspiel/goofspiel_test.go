@@ -0,0 +1,242 @@
+package goofspiel
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+	"github.com/tam0705/go-cfr/internal/cfrtest"
+	"github.com/tam0705/go-cfr/sampling"
+	"github.com/tam0705/go-cfr/tree"
+)
+
+func TestGameTree(t *testing.T) {
+	root := NewGame(Params{Cards: 3, PointOrder: Ascending(3)})
+	// Each turn the players bid one of their n remaining cards,
+	// for n = 3, 2, 1.
+	if n := tree.CountNodes(root); n != 1+3+9+18+36+36+36 {
+		t.Errorf("expected 139 nodes, got %d", n)
+	}
+
+	if n := tree.CountTerminalNodes(root); n != 36 {
+		t.Errorf("expected 36 terminal nodes, got %d", n)
+	}
+
+	// Per player: one infoset in the first turn, then one per
+	// revealed pair of bids.
+	if n := tree.CountInfoSets(root); n != 2*(1+9+36) {
+		t.Errorf("expected 92 infosets, got %d", n)
+	}
+
+	for _, params := range []Params{
+		{Cards: 1, PointOrder: Ascending(1)},
+		{Cards: 3, PointOrder: Descending(3)},
+		{Cards: 3},
+		{Cards: 4, PointOrder: []int{2, 4, 1, 3}},
+	} {
+		if problems := tree.Validate(NewGame(params)); len(problems) > 0 {
+			t.Errorf("%+v: invalid game tree: %v", params, problems)
+		}
+	}
+}
+
+// play returns the node reached by bidding the given cards, alternating
+// between player 0 and player 1. Chance nodes reveal the lowest point card.
+func play(params Params, bids ...int) cfr.GameTreeNode {
+	var node cfr.GameTreeNode = NewGame(params)
+	for _, bid := range bids {
+		if node.Type() == cfr.ChanceNodeType {
+			node = node.GetChild(0)
+		}
+
+		hand := cards(node.(*GoofspielNode).hands[node.Player()])
+		for i, card := range hand {
+			if card == bid {
+				node = node.GetChild(i)
+				break
+			}
+		}
+	}
+
+	return node
+}
+
+func TestUtility(t *testing.T) {
+	for _, tc := range []struct {
+		params Params
+		bids   []int
+		want   float64
+	}{
+		// Player 0 wins 1 and 3, player 1 wins 2.
+		{Params{Cards: 3, PointOrder: Ascending(3)}, []int{2, 1, 1, 3, 3, 2}, 2},
+		// The bids for 3 tie, player 1 wins 2 and player 0 wins 1.
+		{Params{Cards: 3, PointOrder: Descending(3)}, []int{2, 2, 1, 3, 3, 1}, -1},
+		// Player 1 wins 4, 3 and 1, player 0 wins 2.
+		{Params{Cards: 4, PointOrder: Descending(4)}, []int{1, 2, 2, 3, 4, 1, 3, 4}, -6},
+		// Point cards revealed in ascending order by chance.
+		{Params{Cards: 3}, []int{3, 2, 1, 3, 2, 1}, 2},
+	} {
+		node := play(tc.params, tc.bids...)
+		if node.Type() != cfr.TerminalNodeType {
+			t.Errorf("%v: expected terminal node, got %v", node, node.Type())
+			continue
+		}
+
+		if u := node.Utility(NODE_P0); u != tc.want {
+			t.Errorf("%v: expected utility %v for player 0, got %v", node, tc.want, u)
+		}
+		if u := node.Utility(NODE_P1); u != -tc.want {
+			t.Errorf("%v: expected utility %v for player 1, got %v", node, -tc.want, u)
+		}
+	}
+}
+
+func TestInvalidPointOrder(t *testing.T) {
+	for _, order := range [][]int{
+		{1, 2},    // Too short.
+		{0, 1, 2}, // Out of range.
+		{1, 2, 4}, // Out of range.
+		{1, 3, 3}, // Duplicate.
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for point order %v", order)
+				}
+			}()
+
+			NewGame(Params{Cards: 3, PointOrder: order})
+		}()
+	}
+}
+
+func TestInfoSet(t *testing.T) {
+	params := Params{Cards: 3, PointOrder: Ascending(3)}
+	node := play(params, 2, 1, 3)
+	if node.Player() != NODE_P1 {
+		t.Fatalf("expected player 1's turn, got %v", node)
+	}
+
+	// Player 1 does not observe player 0's current bid.
+	other := play(params, 2, 1, 1)
+	if string(node.InfoSetKey(NODE_P1)) != string(other.InfoSetKey(NODE_P1)) {
+		t.Errorf("player 1's infoset depends on player 0's bid: %v, %v", node, other)
+	}
+	if string(node.InfoSetKey(NODE_P0)) == string(other.InfoSetKey(NODE_P0)) {
+		t.Errorf("player 0's infoset does not depend on their bid: %v, %v", node, other)
+	}
+
+	// Both players observe the bids of the previous turns.
+	if string(node.InfoSetKey(NODE_P1)) == string(play(params, 2, 3, 3).InfoSetKey(NODE_P1)) {
+		t.Error("player 1's infoset does not depend on the previous bids")
+	}
+
+	// The players' infosets differ, even with the same observations.
+	player0 := play(params, 2, 1)
+	if string(player0.InfoSetKey(NODE_P0)) == string(node.InfoSetKey(NODE_P1)) {
+		t.Error("players 0 and 1 have the same infoset key")
+	}
+
+	is := node.InfoSet(NODE_P1).(*InfoSet)
+	buf, err := is.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded InfoSet
+	if err := decoded.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded != *is {
+		t.Errorf("expected %+v, got %+v", is, decoded)
+	}
+}
+
+// recordingNode records the actions taken at each of player 1's infosets.
+type recordingNode struct {
+	*GoofspielNode
+	actions map[string]map[int]bool
+}
+
+func (n recordingNode) GetChild(i int) cfr.GameTreeNode {
+	if n.Player() == NODE_P1 {
+		key := string(n.InfoSetKey(NODE_P1))
+		if n.actions[key] == nil {
+			n.actions[key] = make(map[int]bool)
+		}
+		n.actions[key][i] = true
+	}
+
+	return recordingNode{n.GoofspielNode.GetChild(i).(*GoofspielNode), n.actions}
+}
+
+func TestSampledActions(t *testing.T) {
+	params := Params{Cards: 3, PointOrder: Ascending(3)}
+	profile := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewMCCFR(profile, sampling.NewExternalSampler())
+	for i := 0; i < 100; i++ {
+		// Player 0 is the traversing player in odd iterations.
+		root := recordingNode{NewGame(params), make(map[string]map[int]bool)}
+		opt.Run(root)
+		profile.Update()
+		opt.Run(NewGame(params))
+		profile.Update()
+
+		// The sampled action must be the same at every node of an infoset,
+		// regardless of player 0's hidden bid.
+		for key, actions := range root.actions {
+			if len(actions) != 1 {
+				t.Fatalf("iteration %d: sampled actions %v at player 1's infoset %q",
+					2*i+1, actions, key)
+			}
+		}
+
+		if len(root.actions) == 0 {
+			t.Fatal("no actions sampled at player 1's infosets")
+		}
+	}
+}
+
+// The game is symmetric, so its value is zero. If player 1 could observe
+// player 0's bid, they would win every point card.
+func TestCompiledCFR(t *testing.T) {
+	for _, params := range []Params{
+		{Cards: 3, PointOrder: Ascending(3)},
+		{Cards: 3},
+	} {
+		compiled, err := tree.Compile(context.Background(), NewGame(params), 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c := tree.NewCompiledCFR(compiled, cfr.DiscountParams{LinearWeighting: true})
+		for i := 0; i < 2000; i++ {
+			c.Run()
+		}
+
+		ev := tree.ExpectedValue(NewGame(params), c.GetNodeAverageStrategy)
+
+		if math.Abs(ev) > 0.01 {
+			t.Errorf("%+v: expected game value 0, got %.4f", params, ev)
+		}
+	}
+}
+
+func TestMCCFR(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping MCCFR convergence test in short mode")
+	}
+
+	params := Params{Cards: 3}
+	for _, sampler := range []cfr.Sampler{
+		sampling.NewExternalSampler(),
+		sampling.NewOutcomeSampler(0.6),
+	} {
+		profile := cfr.NewPolicyTable(cfr.DiscountParams{LinearWeighting: true})
+		cfrtest.Train(profile, NewGame(params), sampler, 50000)
+		cfrtest.CheckGameValue(t, fmt.Sprintf("%T", sampler), NewGame(params), profile, 0, 0.05)
+	}
+}
@@ -0,0 +1,99 @@
+package holdem
+
+import (
+	"math/rand"
+)
+
+// aliasTable samples from a fixed discrete distribution in O(1)
+// using Walker's alias method.
+type aliasTable struct {
+	prob  []float64
+	alias []int
+}
+
+// newAliasTable builds the alias table for the given distribution.
+// The probabilities need not be normalized.
+func newAliasTable(p []float64) *aliasTable {
+	n := len(p)
+	t := &aliasTable{
+		prob:  make([]float64, n),
+		alias: make([]int, n),
+	}
+
+	var total float64
+	for _, x := range p {
+		total += x
+	}
+
+	// Scale so that the average bucket has probability 1, and split
+	// the buckets into those that are under- and over-full.
+	scaled := make([]float64, n)
+	var small, large []int
+	for i, x := range p {
+		scaled[i] = x * float64(n) / total
+		if scaled[i] < 1.0 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+
+	// Fill each under-full bucket with the excess of an over-full one.
+	for len(small) > 0 && len(large) > 0 {
+		s := small[len(small)-1]
+		small = small[:len(small)-1]
+		l := large[len(large)-1]
+
+		t.prob[s] = scaled[s]
+		t.alias[s] = l
+		scaled[l] -= 1.0 - scaled[s]
+		if scaled[l] < 1.0 {
+			large = large[:len(large)-1]
+			small = append(small, l)
+		}
+	}
+
+	// Whatever remains is full, up to floating point error.
+	for _, i := range large {
+		t.prob[i] = 1.0
+		t.alias[i] = i
+	}
+	for _, i := range small {
+		t.prob[i] = 1.0
+		t.alias[i] = i
+	}
+
+	return t
+}
+
+// Sample returns an index drawn from the table's distribution.
+func (t *aliasTable) Sample() int {
+	i := rand.Intn(len(t.prob))
+	if rand.Float64() < t.prob[i] {
+		return i
+	}
+
+	return t.alias[i]
+}
+
+var (
+	aliasPreflop  *aliasTable
+	aliasFlop     [len(PROB_FLOP_DETAIL)]*aliasTable
+	aliasTurn     [len(PROB_TURN_DETAIL)]*aliasTable
+	aliasRiver    [len(PROB_RIVER_DETAIL)]*aliasTable
+	aliasShowdown *aliasTable
+)
+
+func init() {
+	aliasPreflop = newAliasTable(PROB_PREFLOP[:])
+	for i := range PROB_FLOP_DETAIL {
+		aliasFlop[i] = newAliasTable(PROB_FLOP_DETAIL[i][:])
+	}
+	for i := range PROB_TURN_DETAIL {
+		aliasTurn[i] = newAliasTable(PROB_TURN_DETAIL[i][:])
+	}
+	for i := range PROB_RIVER_DETAIL {
+		aliasRiver[i] = newAliasTable(PROB_RIVER_DETAIL[i][:])
+	}
+	aliasShowdown = newAliasTable(PROB_POSTFLOP[2][:])
+}
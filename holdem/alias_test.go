@@ -0,0 +1,58 @@
+package holdem
+
+import (
+	"math"
+	"testing"
+)
+
+// checkFrequencies draws n samples and checks each empirical frequency is
+// within 5 standard errors of the expected probability.
+func checkFrequencies(t *testing.T, name string, p []float64, sample func() int) {
+	const n = 200000
+	var total float64
+	for _, x := range p {
+		total += x
+	}
+
+	counts := make([]int, len(p))
+	for i := 0; i < n; i++ {
+		counts[sample()]++
+	}
+
+	for i, c := range counts {
+		expected := p[i] / total
+		freq := float64(c) / n
+		stdErr := math.Sqrt(expected * (1 - expected) / n)
+		if math.Abs(freq-expected) > 5*stdErr+1e-6 {
+			t.Errorf("%s: outcome %d sampled with frequency %.6f, expected %.6f",
+				name, i, freq, expected)
+		}
+	}
+}
+
+func TestAliasTable(t *testing.T) {
+	checkFrequencies(t, "preflop", PROB_PREFLOP[:], aliasPreflop.Sample)
+	for i := range PROB_FLOP_DETAIL {
+		checkFrequencies(t, "flop", PROB_FLOP_DETAIL[i][:], aliasFlop[i].Sample)
+	}
+	for i := range PROB_TURN_DETAIL {
+		checkFrequencies(t, "turn", PROB_TURN_DETAIL[i][:], aliasTurn[i].Sample)
+	}
+	for i := range PROB_RIVER_DETAIL {
+		checkFrequencies(t, "river", PROB_RIVER_DETAIL[i][:], aliasRiver[i].Sample)
+	}
+	checkFrequencies(t, "showdown", PROB_POSTFLOP[2][:], aliasShowdown.Sample)
+}
+
+func TestSampleChildFollowsPreflopProbabilities(t *testing.T) {
+	root := &PokerNode{player: NODE_CHANCE}
+	children := make(map[string]int)
+	for i, potential := range HAND_POTENTIAL {
+		children[string([]byte{potential})] = i
+	}
+
+	checkFrequencies(t, "root", PROB_PREFLOP[:], func() int {
+		child, _ := root.SampleChild()
+		return children[child.(*PokerNode).history]
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"math/rand"
 
 	"github.com/tam0705/go-cfr"
+	"github.com/tam0705/go-cfr/sampling"
 )
 
 const (
@@ -44,6 +45,13 @@ var HAND_STRENGTH = [7]byte{
 	'G', // highCard
 }
 
+const (
+	STREET_PREFLOP = iota
+	STREET_FLOP
+	STREET_TURN
+	STREET_RIVER
+)
+
 // Encoding for remaining num of opponents + num of raises (H to u, some skipped)
 var ENC_OPPONENT = [2][]byte{
 	{'I', 'J', 'K', 'L', '!'}, // 8-4 opponents
@@ -91,12 +99,14 @@ var PROB_POSTFLOP = [4][7]float64{
 	{0.0003, 0.0277, 0.0765, 0.0483, 0.2350, 0.4380, 0.1740},
 }
 
-// PokerNode implements cfr.GameTreeNode for Kuhn Poker.
+// PokerNode implements cfr.GameTreeNode for the abstracted Texas hold'em game.
+// See the kuhn package for a small reference game.
 type PokerNode struct {
 	parent        *PokerNode
 	player        int
 	children      []PokerNode
 	probabilities []float64
+	alias         *aliasTable
 	history       string
 
 	handStrength string
@@ -122,6 +132,7 @@ func (k PokerNode) String() string {
 func (k *PokerNode) Close() {
 	k.children = nil
 	k.probabilities = nil
+	k.alias = nil
 }
 
 // NumChildren implements cfr.GameTreeNode.
@@ -165,8 +176,21 @@ func (k *PokerNode) GetChildProbability(i int) float64 {
 }
 
 // SampleChild implements cfr.GameTreeNode.
+//
+// Children are sampled according to the abstraction's probability tables
+// (PROB_PREFLOP, PROB_FLOP_DETAIL, ...) using precomputed alias tables.
 func (k *PokerNode) SampleChild() (cfr.GameTreeNode, float64) {
-	i := rand.Intn(k.NumChildren())
+	if k.children == nil {
+		k.buildChildren()
+	}
+
+	var i int
+	if k.alias != nil {
+		i = k.alias.Sample()
+	} else {
+		i = rand.Intn(len(k.children))
+	}
+
 	return k.GetChild(i), k.GetChildProbability(i)
 }
 
@@ -215,6 +239,12 @@ func (k *PokerNode) GetNode(history string) cfr.GameTreeNode {
 }
 
 func GetStrategy(history string) []float64 {
+	return GetLegalStrategy(history, nil)
+}
+
+// GetLegalStrategy returns the strategy for the given history, restricted
+// to the actions allowed by legal and renormalized.
+func GetLegalStrategy(history string, legal []bool) []float64 {
 	policyData, ok := policy.GetPolicyByKey(history)
 
 	if !ok {
@@ -222,7 +252,7 @@ func GetStrategy(history string) []float64 {
 		policyData, ok = policy.GetPolicyByKey(history)
 	}
 
-	strat := policyData.GetStrategy()
+	strat := policyData.GetLegalStrategy(legal)
 	strat64 := make([]float64, len(strat))
 	for i, s := range strat {
 		strat64[i] = float64(s)
@@ -249,8 +279,12 @@ func (k *PokerNode) Utility(player int) float64 {
 	raiseArr := make([]float64, 0)
 	for i, b := range k.history {
 		if b == 'r' {
-			policyData, _ := policy.GetPolicyByKey(k.history[:i])
-			raiseArr = append(raiseArr, float64((policyData.GetStrategy())[2]))
+			// A node that has not been visited plays its 4 actions uniformly.
+			raise := float32(0.25)
+			if policyData, ok := policy.GetPolicyByKey(k.history[:i]); ok {
+				raise = policyData.GetStrategy()[2]
+			}
+			raiseArr = append(raiseArr, float64(raise))
 		}
 	}
 	total, betPos := RewardCounter(k.history, raiseArr, int64(len(raiseArr)))
@@ -322,6 +356,15 @@ func (k *PokerNode) InfoSetKey(player int) []byte {
 	return k.InfoSet(player).Key()
 }
 
+// LegalActions implements cfr.GameTreeNode.
+//
+// The abstraction does not track stack sizes or raise caps, so every
+// child is legal during training. At play time GetDecision restricts the
+// strategy to the actions allowed at the table.
+func (k *PokerNode) LegalActions() []bool {
+	return nil
+}
+
 func uniformDist(n int) []float64 {
 	result := make([]float64, n)
 	num := 1.0 / float64(n)
@@ -355,6 +398,7 @@ func (k *PokerNode) buildChildren() {
 	case 0:
 		k.children = buildPreflop(k)
 		k.probabilities = PROB_PREFLOP[:]
+		k.alias = aliasPreflop
 	case 1, 4, 7, 10:
 		k.children = buildOpponentDeals(k)
 	case 2, 5, 8, 11:
@@ -367,10 +411,13 @@ func (k *PokerNode) buildChildren() {
 			//NEW FEATURE
 			if len(k.history) == 3 {
 				k.probabilities = PROB_FLOP_DETAIL[k.history[0]-'0'][:]
+				k.alias = aliasFlop[k.history[0]-'0']
 			} else if len(k.history) == 6 {
 				k.probabilities = PROB_TURN_DETAIL[k.history[3]-'A'][:]
+				k.alias = aliasTurn[k.history[3]-'A']
 			} else {
 				k.probabilities = PROB_RIVER_DETAIL[k.history[6]-'A'][:]
+				k.alias = aliasRiver[k.history[6]-'A']
 			}
 		}
 	default:
@@ -378,6 +425,7 @@ func (k *PokerNode) buildChildren() {
 			k.history[len(k.history)-1] != ACTION_ALL_IN && k.opponentNum > 0 {
 			k.children = buildPostflop(k, true)
 			k.probabilities = PROB_POSTFLOP[2][:]
+			k.alias = aliasShowdown
 		}
 	}
 }
@@ -461,6 +509,24 @@ func buildAIDeals(parent *PokerNode) []PokerNode {
 	return result
 }
 
+// GetStreet returns the betting round (STREET_PREFLOP, ...) of the given
+// history. Each street adds a hand strength, opponent encoding and action.
+func GetStreet(history string) int {
+	if len(history) == 0 {
+		return STREET_PREFLOP
+	}
+
+	return (len(history) - 1) / 3
+}
+
+// ByStreet returns a sampling.Rule for PokerNode trees that selects
+// sampler i for nodes on street i (preflop, flop, turn and river).
+func ByStreet() sampling.Rule {
+	return sampling.ByInfoSetKey(func(key []byte) int {
+		return GetStreet(string(key))
+	})
+}
+
 func GetOpponentInfo(char byte) (int, int) {
 	for i, slice := range ENC_OPPONENT {
 		for _, e := range slice {
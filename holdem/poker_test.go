@@ -0,0 +1,32 @@
+package holdem
+
+import (
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// keyedNode is a minimal cfr.GameTreeNode with an InfoSet key.
+type keyedNode struct {
+	cfr.GameTreeNode
+	key string
+}
+
+func (n *keyedNode) Player() int                  { return NODE_AI }
+func (n *keyedNode) InfoSetKey(player int) []byte { return []byte(n.key) }
+
+func TestByStreet(t *testing.T) {
+	rule := ByStreet()
+	for _, tc := range []struct {
+		key    string
+		street int
+	}{
+		{"3K", STREET_PREFLOP},
+		{"3KcF", STREET_FLOP},
+		{"3KcFKcFKcF!", STREET_RIVER},
+	} {
+		if street := rule(&keyedNode{key: tc.key}, cfr.SampleContext{}); street != tc.street {
+			t.Errorf("%q: expected street %d, got %d", tc.key, tc.street, street)
+		}
+	}
+}
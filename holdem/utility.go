@@ -182,9 +182,22 @@ func GetDecision(Informations Def.RobotInherit, Standard, Total, RaiseDiff, AllI
 		myHistory += OpponentRaiseEncoding(int(Informations.PlayerNum)-1, int(Informations.RaiseCounter-Informations.RaiseSelf))
 	}
 
+	//Fold - Call/Check - Raise - All in, It is always possible for Folding
+	var canCheck bool = Standard == Informations.BetPos
+	var legal []bool = []bool{true, false, false, true}
+	//consider the available move for call/check, cant happen at same time
+	if canCheck || Standard < Informations.ContestMoney {
+		legal[1] = true
+	}
+
+	//consider availability for raise
+	if (RaiseDiff+Standard-Informations.BetPos) < Informations.ContestMoney && (RaiseDiff+Standard) < AllInBound && Standard < Informations.SbBet*2*RAISE_LIMIT_MULTIPLIER {
+		legal[2] = true
+	}
+
 	//historyReady
 	var myStrategy []float64
-	myStrategy = GetStrategy(myHistory)
+	myStrategy = GetLegalStrategy(myHistory, legal)
 	if FINETUNE_ON {
 		var raisePass float64 = myStrategy[2] * RAISE_REDUCE
 		var allInPass float64 = myStrategy[3] * ALLIN_REDUCE
@@ -234,33 +247,14 @@ func GetDecision(Informations Def.RobotInherit, Standard, Total, RaiseDiff, AllI
 		}
 	}
 
-	//Fold - Call - Check - Fold - Raise, It is always possible for Folding
-	var myAvailableAction [5]string = [5]string{"1", "0", "0", "0", "1"}
-	//consider the available move for call/check, cant happen at same time
-	if Standard == Informations.BetPos {
-		myAvailableAction[1] = "1" //return
-	} else if Standard < Informations.ContestMoney {
-		myAvailableAction[2] = "1"
-	}
-
-	//consider availability for raise
-	if (RaiseDiff+Standard-Informations.BetPos) < Informations.ContestMoney && (RaiseDiff+Standard) < AllInBound && Standard < Informations.SbBet*2*RAISE_LIMIT_MULTIPLIER {
-		myAvailableAction[3] = "1"
-	}
-
 	randomFloat := rand.Float64()
 	myAction := Def.PLAYER_ACTION_CALL
 	var myBet float64 = 0.0
-	//fold call/check raise and all in
-	//check the availability
-	if myAvailableAction[1] == "0" && myAvailableAction[2] == "0" {
-		myStrategy[1] = 0.0
-	}
-	if myAvailableAction[3] == "0" {
-		myStrategy[2] = 0.0
-	}
-	if myAvailableAction[4] == "0" {
-		myStrategy[3] = 0.0
+	//fine tuning may have moved weight onto unavailable actions
+	for a := 0; a < 4; a++ {
+		if !legal[a] {
+			myStrategy[a] = 0.0
+		}
 	}
 
 	//if there is a bit of money left then let the call get higher
@@ -291,7 +285,7 @@ func GetDecision(Informations Def.RobotInherit, Standard, Total, RaiseDiff, AllI
 			myBet = 0.0
 			myHistory += "f"
 		} else if randomFloat < myStrategy[0]+myStrategy[1] {
-			if myAvailableAction[1] == "1" {
+			if canCheck {
 				myAction = Def.PLAYER_ACTION_CHECK
 				myBet = 0.0
 				myHistory += "c"
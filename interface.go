@@ -2,6 +2,7 @@ package cfr
 
 import (
 	"encoding"
+	"fmt"
 	"io"
 )
 
@@ -14,6 +15,20 @@ const (
 	PlayerNodeType
 )
 
+// String implements fmt.Stringer.
+func (t NodeType) String() string {
+	switch t {
+	case ChanceNodeType:
+		return "chance"
+	case TerminalNodeType:
+		return "terminal"
+	case PlayerNodeType:
+		return "player"
+	default:
+		return fmt.Sprintf("NodeType(%d)", t)
+	}
+}
+
 // InfoSet is the observable game history from the point of view of one player.
 type InfoSet interface {
 	// Key is an identifier used to uniquely look up this InfoSet
@@ -52,6 +67,12 @@ type PlayerNode interface {
 	// InfoSetKey returns the equivalent of InfoSet(player).Key(),
 	// but can be used to avoid allocations incurred by the InfoSet interface.
 	InfoSetKey(player int) []byte
+	// LegalActions returns a mask over the children of this node, where
+	// mask[i] reports whether the ith action may be played here. The same
+	// InfoSet may appear at nodes with different legal actions, but must
+	// always have the same number of children. A nil mask means every
+	// child is legal.
+	LegalActions() []bool
 	// Utility returns this node's utility for the given player.
 	// It must only be called for nodes with type == Terminal.
 	Utility(player int) float64
@@ -109,6 +130,12 @@ type NodePolicy interface {
 	// GetStrategy gets the current vector of probabilities with which the ith
 	// available action should be played.
 	GetStrategy() []float32
+	// GetLegalStrategy returns the current strategy restricted to the actions
+	// allowed by mask, renormalized to sum to 1. If no legal action has
+	// positive probability the result is uniform over the legal actions.
+	// A nil mask is equivalent to GetStrategy. The returned slice may be
+	// reused between calls.
+	GetLegalStrategy(mask []bool) []float32
 	SetStrategy(strat []float32)
 
 	NextStrategy(discountPositiveRegret, discountNegativeRegret, discountstrategySum float32)
@@ -121,8 +148,18 @@ type NodePolicy interface {
 
 	// AddStrategyWeight adds the current strategy with weight w to the average.
 	AddStrategyWeight(w float32)
+	// AddLegalStrategyWeight adds the current strategy, restricted to the
+	// actions allowed by mask, with weight w to the average.
+	AddLegalStrategyWeight(w float32, mask []bool)
 	// GetAverageStrategy returns the average strategy over all iterations.
 	GetAverageStrategy() []float32
+	// GetStrategySum returns the accumulated (unnormalized) strategy weights
+	// of each action, which GetAverageStrategy normalizes.
+	GetStrategySum() []float32
+	// GetStrategyWeightScale returns the factor by which the strategy sums
+	// have been scaled relative to the weights added, which is 1 unless
+	// they have been renormalized to prevent overflow.
+	GetStrategyWeightScale() float64
 
 	// IsEmpty returns true if the NodePolicy is new and has no accumulated regret.
 	IsEmpty() bool
@@ -0,0 +1,32 @@
+// Package cfrtest contains helpers shared by the tests of the game packages.
+package cfrtest
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+	"github.com/tam0705/go-cfr/tree"
+)
+
+// Train runs nIter iterations of MCCFR with the given sampler on the game
+// tree rooted at root, updating profile after each one.
+func Train(profile cfr.StrategyProfile, root cfr.GameTreeNode, sampler cfr.Sampler, nIter int) {
+	opt := cfr.NewMCCFR(profile, sampler)
+	for i := 0; i < nIter; i++ {
+		opt.Run(root)
+		profile.Update()
+	}
+}
+
+// CheckGameValue reports an error if the value for player 0 of the average
+// strategies in profile differs from want by more than tol.
+func CheckGameValue(t testing.TB, name string, root cfr.GameTreeNode, profile cfr.StrategyProfile, want, tol float64) {
+	t.Helper()
+	ev := tree.ExpectedValue(root, tree.ProfileAverageStrategy(profile))
+	if math.Abs(ev-want) > tol {
+		t.Errorf("%s: expected game value %.4f, got %.4f", name, want, ev)
+	} else {
+		t.Logf("%s: game value %.4f", name, ev)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright ©2016 The Gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//+build !noasm,!appengine,!safe
+
+#include "textflag.h"
+
+#define DST_PTR DI
+#define S_PTR SI
+#define LEN CX
+#define TAIL BX
+#define IDX AX
+
+// func Div(dst, s []float32)
+TEXT ·Div(SB), NOSPLIT, $0
+	MOVQ dst_base+0(FP), DST_PTR // DST_PTR = &dst
+	MOVQ s_base+24(FP), S_PTR    // S_PTR = &s
+	MOVQ s_len+32(FP), LEN       // LEN = len(s)
+	CMPQ LEN, $0
+	JE   div_end                 // if LEN == 0 { return }
+	XORQ IDX, IDX                // IDX = 0
+	MOVQ LEN, TAIL
+	ANDQ $0xF, TAIL              // TAIL = LEN % 16
+	SHRQ $4, LEN                 // LEN = floor( LEN / 16 )
+	JZ   div_tail4_start         // if LEN == 0 { goto div_tail4_start }
+
+div_loop: // Loop unrolled 16x  do {
+	MOVUPS (DST_PTR)(IDX*4), X0   // X_i = dst[i:i+4]
+	MOVUPS 16(DST_PTR)(IDX*4), X1
+	MOVUPS 32(DST_PTR)(IDX*4), X2
+	MOVUPS 48(DST_PTR)(IDX*4), X3
+	MOVUPS (S_PTR)(IDX*4), X4     // X_j = s[i:i+4]
+	MOVUPS 16(S_PTR)(IDX*4), X5
+	MOVUPS 32(S_PTR)(IDX*4), X6
+	MOVUPS 48(S_PTR)(IDX*4), X7
+	DIVPS  X4, X0                 // X_i /= X_j
+	DIVPS  X5, X1
+	DIVPS  X6, X2
+	DIVPS  X7, X3
+	MOVUPS X0, (DST_PTR)(IDX*4)   // dst[i:i+4] = X_i
+	MOVUPS X1, 16(DST_PTR)(IDX*4)
+	MOVUPS X2, 32(DST_PTR)(IDX*4)
+	MOVUPS X3, 48(DST_PTR)(IDX*4)
+	ADDQ   $16, IDX               // IDX += 16
+	DECQ   LEN
+	JNZ    div_loop               // } while --LEN > 0
+
+div_tail4_start: // Reset loop counter for 4-wide tail loop
+	MOVQ TAIL, LEN      // LEN = floor( TAIL / 4 )
+	SHRQ $2, LEN
+	JZ   div_tail_start // if LEN == 0 { goto div_tail_start }
+
+div_tail4: // Loop unrolled 4x  do {
+	MOVUPS (DST_PTR)(IDX*4), X0 // X0 = dst[i:i+4]
+	MOVUPS (S_PTR)(IDX*4), X1   // X1 = s[i:i+4]
+	DIVPS  X1, X0               // X0 /= X1
+	MOVUPS X0, (DST_PTR)(IDX*4) // dst[i:i+4] = X0
+	ADDQ   $4, IDX              // IDX += 4
+	DECQ   LEN
+	JNZ    div_tail4            // } while --LEN > 0
+
+div_tail_start: // Reset loop counter for 1-wide tail loop
+	ANDQ $3, TAIL // TAIL = TAIL % 4
+	JZ   div_end  // if TAIL == 0 { return }
+
+div_tail: // do {
+	MOVSS (DST_PTR)(IDX*4), X0 // X0 = dst[i]
+	DIVSS (S_PTR)(IDX*4), X0   // X0 /= s[i]
+	MOVSS X0, (DST_PTR)(IDX*4) // dst[i] = X0
+	INCQ  IDX                  // IDX++
+	DECQ  TAIL
+	JNZ   div_tail             // } while --TAIL > 0
+
+div_end:
+	RET
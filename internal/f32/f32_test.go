@@ -0,0 +1,274 @@
+package f32
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// Reference implementations, against which the (possibly assembly)
+// implementations are checked.
+
+func scalUnitaryRef(alpha float32, x []float32) {
+	for i := range x {
+		x[i] *= alpha
+	}
+}
+
+func scalUnitaryToRef(dst []float32, alpha float32, x []float32) {
+	for i, v := range x {
+		dst[i] = alpha * v
+	}
+}
+
+func addConstRef(alpha float32, x []float32) {
+	for i := range x {
+		x[i] += alpha
+	}
+}
+
+func divRef(dst, s []float32) {
+	for i, v := range s {
+		dst[i] /= v
+	}
+}
+
+func divToRef(dst, s, t []float32) []float32 {
+	for i, v := range s {
+		dst[i] = v / t[i]
+	}
+	return dst
+}
+
+func sumRef(x []float32) float32 {
+	var sum float32
+	for _, v := range x {
+		sum += v
+	}
+	return sum
+}
+
+// guard is written around test slices to detect out of bounds writes.
+const guard = -12345.0
+
+// guarded returns a copy of x, offset by the given number of elements
+// in a larger slice filled with guard values.
+func guarded(x []float32, offset int) []float32 {
+	buf := make([]float32, len(x)+offset+4)
+	for i := range buf {
+		buf[i] = guard
+	}
+
+	copy(buf[offset:], x)
+	return buf[offset : offset+len(x)]
+}
+
+func checkGuards(t *testing.T, name string, x []float32) {
+	full := x[:cap(x)]
+	for i := len(x); i < len(full); i++ {
+		if full[i] != guard {
+			t.Fatalf("%s: wrote past end of slice at %d: %v", name, i, full[i])
+		}
+	}
+}
+
+func randomSlice(rng *rand.Rand, n int) []float32 {
+	x := make([]float32, n)
+	for i := range x {
+		x[i] = float32(rng.NormFloat64())
+	}
+
+	return x
+}
+
+func sameFloats(a, b []float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] && !(isNaN(a[i]) && isNaN(b[i])) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isNaN(x float32) bool {
+	return x != x
+}
+
+// closeSum returns whether two sums of x, computed in a different order,
+// are equal up to rounding error.
+func closeSum(a, b float32, x []float32) bool {
+	var absSum float64
+	for _, v := range x {
+		absSum += math.Abs(float64(v))
+	}
+
+	if !(absSum < math.MaxFloat32/2) {
+		// Whether the sum overflows (or is NaN) depends on the order of summation.
+		return true
+	}
+
+	tol := 1e-6*absSum*float64(len(x)) + 1e-30
+	return math.Abs(float64(a-b)) <= tol
+}
+
+func TestElementwise(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 70; n++ {
+		for offset := 0; offset < 4; offset++ {
+			alpha := float32(rng.NormFloat64())
+			x := randomSlice(rng, n)
+			y := randomSlice(rng, n)
+			checkElementwise(t, alpha, x, y, offset)
+		}
+	}
+}
+
+func checkElementwise(t *testing.T, alpha float32, x, y []float32, offset int) {
+	n := len(x)
+
+	got, want := guarded(x, offset), append([]float32(nil), x...)
+	ScalUnitary(alpha, got)
+	scalUnitaryRef(alpha, want)
+	if !sameFloats(got, want) {
+		t.Errorf("ScalUnitary(%v, n=%d, offset=%d): got %v, want %v", alpha, n, offset, got, want)
+	}
+	checkGuards(t, "ScalUnitary", got)
+
+	got, want = guarded(make([]float32, n), offset), make([]float32, n)
+	ScalUnitaryTo(got, alpha, x)
+	scalUnitaryToRef(want, alpha, x)
+	if !sameFloats(got, want) {
+		t.Errorf("ScalUnitaryTo(%v, n=%d, offset=%d): got %v, want %v", alpha, n, offset, got, want)
+	}
+	checkGuards(t, "ScalUnitaryTo", got)
+
+	got, want = guarded(x, offset), append([]float32(nil), x...)
+	AddConst(alpha, got)
+	addConstRef(alpha, want)
+	if !sameFloats(got, want) {
+		t.Errorf("AddConst(%v, n=%d, offset=%d): got %v, want %v", alpha, n, offset, got, want)
+	}
+	checkGuards(t, "AddConst", got)
+
+	got, want = guarded(x, offset), append([]float32(nil), x...)
+	Div(got, y)
+	divRef(want, y)
+	if !sameFloats(got, want) {
+		t.Errorf("Div(n=%d, offset=%d): got %v, want %v", n, offset, got, want)
+	}
+	checkGuards(t, "Div", got)
+
+	got, want = guarded(make([]float32, n), offset), make([]float32, n)
+	ret := DivTo(got, x, y)
+	divToRef(want, x, y)
+	if !sameFloats(got, want) {
+		t.Errorf("DivTo(n=%d, offset=%d): got %v, want %v", n, offset, got, want)
+	}
+	if len(ret) != len(got) || cap(ret) != cap(got) || (n > 0 && &ret[0] != &got[0]) {
+		t.Errorf("DivTo(n=%d, offset=%d): did not return dst", n, offset)
+	}
+	checkGuards(t, "DivTo", got)
+
+	if s, want := Sum(guarded(x, offset)), sumRef(x); !closeSum(s, want, x) {
+		t.Errorf("Sum(n=%d, offset=%d): got %v, want %v", n, offset, s, want)
+	}
+}
+
+func TestSpecialValues(t *testing.T) {
+	inf := float32(math.Inf(1))
+	nan := float32(math.NaN())
+	x := []float32{0, -0, 1, -1, inf, -inf, nan, 1e-40, 3.4e38, -3.4e38}
+	y := []float32{0, 1, -0, inf, inf, 2, 1, 1e-40, 0.5, 3.4e38}
+	for _, alpha := range []float32{0, 1, -1, 2, inf, nan} {
+		checkElementwise(t, alpha, x, y, 1)
+	}
+}
+
+var benchSizes = []struct {
+	name string
+	n    int
+}{
+	{"4", 4},
+	{"17", 17},
+	{"100", 100},
+	{"10000", 10000},
+}
+
+func BenchmarkScalUnitary(b *testing.B) {
+	for _, size := range benchSizes {
+		x := randomSlice(rand.New(rand.NewSource(1)), size.n)
+		b.Run(size.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ScalUnitary(1.0, x)
+			}
+		})
+	}
+}
+
+func BenchmarkScalUnitaryTo(b *testing.B) {
+	for _, size := range benchSizes {
+		x := randomSlice(rand.New(rand.NewSource(1)), size.n)
+		dst := make([]float32, size.n)
+		b.Run(size.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ScalUnitaryTo(dst, 0.5, x)
+			}
+		})
+	}
+}
+
+func BenchmarkAddConst(b *testing.B) {
+	for _, size := range benchSizes {
+		x := randomSlice(rand.New(rand.NewSource(1)), size.n)
+		b.Run(size.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				AddConst(0, x)
+			}
+		})
+	}
+}
+
+func BenchmarkDiv(b *testing.B) {
+	for _, size := range benchSizes {
+		rng := rand.New(rand.NewSource(1))
+		x, y := randomSlice(rng, size.n), randomSlice(rng, size.n)
+		for i := range y {
+			y[i] = 1.0
+		}
+		b.Run(size.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Div(x, y)
+			}
+		})
+	}
+}
+
+func BenchmarkDivTo(b *testing.B) {
+	for _, size := range benchSizes {
+		rng := rand.New(rand.NewSource(1))
+		x, y := randomSlice(rng, size.n), randomSlice(rng, size.n)
+		dst := make([]float32, size.n)
+		b.Run(size.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				DivTo(dst, x, y)
+			}
+		})
+	}
+}
+
+func BenchmarkSum(b *testing.B) {
+	for _, size := range benchSizes {
+		x := randomSlice(rand.New(rand.NewSource(1)), size.n)
+		var sum float32
+		b.Run(size.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sum += Sum(x)
+			}
+		})
+	}
+}
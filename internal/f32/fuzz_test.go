@@ -0,0 +1,36 @@
+//go:build go1.18
+// +build go1.18
+
+package f32
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// decodeFloats interprets data as little-endian float32s.
+func decodeFloats(data []byte) []float32 {
+	x := make([]float32, len(data)/4)
+	for i := range x {
+		x[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+
+	return x
+}
+
+func FuzzElementwise(f *testing.F) {
+	f.Add(float32(0.5), []byte{}, uint8(0))
+	f.Add(float32(-2), []byte("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef01234567"), uint8(1))
+	f.Add(float32(math.Inf(1)), []byte{0, 0, 0x80, 0x7f, 0, 0, 0xc0, 0x7f, 1, 0, 0, 0}, uint8(3))
+	f.Fuzz(func(t *testing.T, alpha float32, data []byte, offset uint8) {
+		x := decodeFloats(data)
+		// Use the reversed values as the second operand.
+		y := make([]float32, len(x))
+		for i, v := range x {
+			y[len(y)-1-i] = v
+		}
+
+		checkElementwise(t, alpha, x, y, int(offset%4))
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright ©2016 The Gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//+build !noasm,!appengine,!safe
+
+#include "textflag.h"
+
+#define X_PTR SI
+#define LEN CX
+#define TAIL BX
+#define IDX AX
+#define ALPHA X0
+
+// func ScalUnitary(alpha float32, x []float32)
+TEXT ·ScalUnitary(SB), NOSPLIT, $0
+	MOVQ   x_base+8(FP), X_PTR // X_PTR = &x
+	MOVQ   x_len+16(FP), LEN   // LEN = len(x)
+	CMPQ   LEN, $0
+	JE     scal_end            // if LEN == 0 { return }
+	MOVSS  alpha+0(FP), ALPHA
+	SHUFPS $0, ALPHA, ALPHA    // ALPHA = { a, a, a, a }
+	XORQ   IDX, IDX            // IDX = 0
+	MOVQ   LEN, TAIL
+	ANDQ   $0xF, TAIL          // TAIL = LEN % 16
+	SHRQ   $4, LEN             // LEN = floor( LEN / 16 )
+	JZ     scal_tail4_start    // if LEN == 0 { goto scal_tail4_start }
+
+scal_loop: // Loop unrolled 16x  do {
+	MOVUPS (X_PTR)(IDX*4), X1   // X_i = x[i:i+4]
+	MOVUPS 16(X_PTR)(IDX*4), X2
+	MOVUPS 32(X_PTR)(IDX*4), X3
+	MOVUPS 48(X_PTR)(IDX*4), X4
+	MULPS  ALPHA, X1            // X_i *= a
+	MULPS  ALPHA, X2
+	MULPS  ALPHA, X3
+	MULPS  ALPHA, X4
+	MOVUPS X1, (X_PTR)(IDX*4)   // x[i:i+4] = X_i
+	MOVUPS X2, 16(X_PTR)(IDX*4)
+	MOVUPS X3, 32(X_PTR)(IDX*4)
+	MOVUPS X4, 48(X_PTR)(IDX*4)
+	ADDQ   $16, IDX             // IDX += 16
+	DECQ   LEN
+	JNZ    scal_loop            // } while --LEN > 0
+
+scal_tail4_start: // Reset loop counter for 4-wide tail loop
+	MOVQ TAIL, LEN       // LEN = floor( TAIL / 4 )
+	SHRQ $2, LEN
+	JZ   scal_tail_start // if LEN == 0 { goto scal_tail_start }
+
+scal_tail4: // Loop unrolled 4x  do {
+	MOVUPS (X_PTR)(IDX*4), X1 // X1 = x[i:i+4]
+	MULPS  ALPHA, X1          // X1 *= a
+	MOVUPS X1, (X_PTR)(IDX*4) // x[i:i+4] = X1
+	ADDQ   $4, IDX            // IDX += 4
+	DECQ   LEN
+	JNZ    scal_tail4         // } while --LEN > 0
+
+scal_tail_start: // Reset loop counter for 1-wide tail loop
+	ANDQ $3, TAIL // TAIL = TAIL % 4
+	JZ   scal_end // if TAIL == 0 { return }
+
+scal_tail: // do {
+	MOVSS (X_PTR)(IDX*4), X1 // X1 = x[i]
+	MULSS ALPHA, X1          // X1 *= a
+	MOVSS X1, (X_PTR)(IDX*4) // x[i] = X1
+	INCQ  IDX                // IDX++
+	DECQ  TAIL
+	JNZ   scal_tail          // } while --TAIL > 0
+
+scal_end:
+	RET
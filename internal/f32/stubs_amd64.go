@@ -19,10 +19,40 @@ func AxpyUnitary(alpha float32, x, y []float32)
 //  return sum
 func DotUnitary(x, y []float32) (sum float32)
 
-// TODO: Migrate from gonum/f64:
-// - ScalUnitary
-// - ScalUnitaryTo
-// - Sum
-// - Div
-// - DivTo
-// - AddConst
+// ScalUnitary is
+//  for i := range x {
+//  	x[i] *= alpha
+//  }
+func ScalUnitary(alpha float32, x []float32)
+
+// ScalUnitaryTo is
+//  for i, v := range x {
+//  	dst[i] = alpha * v
+//  }
+func ScalUnitaryTo(dst []float32, alpha float32, x []float32)
+
+// AddConst is
+//  for i := range x {
+//  	x[i] += alpha
+//  }
+func AddConst(alpha float32, x []float32)
+
+// Div is
+//  for i, v := range s {
+//  	dst[i] /= v
+//  }
+func Div(dst, s []float32)
+
+// DivTo is
+//  for i, v := range s {
+//  	dst[i] = v / t[i]
+//  }
+//  return dst
+func DivTo(dst, s, t []float32) []float32
+
+// Sum is
+//  var sum float32
+//  for i := range x {
+//      sum += x[i]
+//  }
+func Sum(x []float32) float32
@@ -0,0 +1,73 @@
+// Copyright ©2016 The Gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//+build !noasm,!appengine,!safe
+
+#include "textflag.h"
+
+#define HADDPS_SUM_SUM    LONG $0xC07C0FF2 // @ HADDPS X0, X0
+
+#define X_PTR SI
+#define LEN CX
+#define TAIL BX
+#define IDX AX
+#define SUM X0
+#define P_SUM X1
+
+// func Sum(x []float32) float32
+TEXT ·Sum(SB), NOSPLIT, $0
+	MOVQ x_base+0(FP), X_PTR // X_PTR = &x
+	MOVQ x_len+8(FP), LEN    // LEN = len(x)
+	PXOR SUM, SUM            // SUM = 0
+	CMPQ LEN, $0
+	JE   sum_end             // if LEN == 0 { return 0 }
+	PXOR P_SUM, P_SUM        // P_SUM = 0  for pipelining
+	XORQ IDX, IDX            // IDX = 0
+	MOVQ LEN, TAIL
+	ANDQ $0xF, TAIL          // TAIL = LEN % 16
+	SHRQ $4, LEN             // LEN = floor( LEN / 16 )
+	JZ   sum_tail4_start     // if LEN == 0 { goto sum_tail4_start }
+
+sum_loop: // Loop unrolled 16x  do {
+	MOVUPS (X_PTR)(IDX*4), X2   // X_i = x[i:i+4]
+	MOVUPS 16(X_PTR)(IDX*4), X3
+	MOVUPS 32(X_PTR)(IDX*4), X4
+	MOVUPS 48(X_PTR)(IDX*4), X5
+	ADDPS  X2, SUM              // SUM += X_i
+	ADDPS  X3, P_SUM
+	ADDPS  X4, SUM
+	ADDPS  X5, P_SUM
+	ADDQ   $16, IDX             // IDX += 16
+	DECQ   LEN
+	JNZ    sum_loop             // } while --LEN > 0
+
+	ADDPS P_SUM, SUM // SUM += P_SUM
+
+sum_tail4_start: // Reset loop counter for 4-wide tail loop
+	MOVQ TAIL, LEN      // LEN = floor( TAIL / 4 )
+	SHRQ $2, LEN
+	JZ   sum_tail_start // if LEN == 0 { goto sum_tail_start }
+
+sum_tail4: // Loop unrolled 4x  do {
+	MOVUPS (X_PTR)(IDX*4), X2 // X2 = x[i:i+4]
+	ADDPS  X2, SUM            // SUM += X2
+	ADDQ   $4, IDX            // IDX += 4
+	DECQ   LEN
+	JNZ    sum_tail4          // } while --LEN > 0
+
+sum_tail_start:
+	HADDPS_SUM_SUM // SUM = \sum{ SUM[i] }
+	HADDPS_SUM_SUM
+	ANDQ $3, TAIL  // TAIL = TAIL % 4
+	JZ   sum_end   // if TAIL == 0 { return SUM }
+
+sum_tail: // do {
+	ADDSS (X_PTR)(IDX*4), SUM // SUM += x[i]
+	INCQ  IDX                 // IDX++
+	DECQ  TAIL
+	JNZ   sum_tail            // } while --TAIL > 0
+
+sum_end:
+	MOVSS SUM, ret+24(FP) // return SUM
+	RET
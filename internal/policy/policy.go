@@ -20,6 +20,32 @@ type Policy struct {
 
 	regretSum   []float32
 	strategySum []float32
+
+	// Strategy weight accumulated under a legal-action mask since the
+	// last call to NextStrategy. Allocated lazily.
+	legalStrategyWeight []float32
+	// Scratch space for GetLegalStrategy.
+	legalStrategy []float32
+
+	// Factor applied to all strategy weight, which Renormalize adjusts
+	// so that the average strategy is unchanged.
+	weightScale float64
+}
+
+// minWeightScale is the smallest weight scale that Renormalize sets.
+// Below it, the strategy sums are no longer rescaled to sum to 1, so that
+// the scale cannot underflow.
+const minWeightScale = 1e-200
+
+// renormalizationFactor returns the factor by which to rescale strategy
+// weights that sum to total and are scaled by weightScale, so that they
+// sum to 1 but the scale stays at least minWeightScale.
+func renormalizationFactor(total, weightScale float64) float64 {
+	if !(total > 0) || math.IsInf(total, 0) {
+		return 1.0
+	}
+
+	return math.Max(1.0/total, minWeightScale/weightScale)
 }
 
 // NewPolicy returns a new Policy for a game node with the given number of actions.
@@ -30,6 +56,7 @@ func New(nActions int) *Policy {
 		baseline:              make([]float32, nActions),
 		regretSum:             make([]float32, nActions),
 		strategySum:           make([]float32, nActions),
+		weightScale:           1.0,
 	}
 }
 
@@ -44,8 +71,10 @@ func (p *Policy) CombineData(np *Policy) {
 	for i,s := range p.regretSum {
 		p.regretSum[i] = (s + np.regretSum[i]) / 2
 	}
+	// Bring np's strategy sums to the same scale before averaging them.
+	rescale := float32(p.weightScale / np.weightScale)
 	for i,s := range p.strategySum {
-		p.strategySum[i] = (s + np.strategySum[i]) / 2
+		p.strategySum[i] = (s + rescale*np.strategySum[i]) / 2
 	}
 }
 
@@ -53,10 +82,29 @@ func (p *Policy) GetStrategy() []float32 {
 	return p.currentStrategy
 }
 
+func (p *Policy) GetLegalStrategy(mask []bool) []float32 {
+	if mask == nil {
+		return p.currentStrategy
+	}
+
+	if p.legalStrategy == nil {
+		p.legalStrategy = make([]float32, len(p.currentStrategy))
+	}
+
+	restrict(p.legalStrategy, p.currentStrategy, mask)
+	return p.legalStrategy
+}
+
 func (p *Policy) SetStrategy(strat []float32) {
 	p.currentStrategy = strat
 }
 
+// SetStrategySum replaces the accumulated strategy sums, which are in the
+// same units as those returned by GetStrategySum.
+func (p *Policy) SetStrategySum(sum []float32) {
+	copy(p.strategySum, sum)
+}
+
 func (p *Policy) IsEmpty() bool {
 	// TODO(palpant): Worth keeping a separate bit?
 	for _, r := range p.regretSum {
@@ -74,6 +122,12 @@ func (p *Policy) NextStrategy(discountPositiveRegret, discountNegativeRegret, di
 	}
 
 	f32.AxpyUnitary(p.currentStrategyWeight, p.currentStrategy, p.strategySum)
+	if p.legalStrategyWeight != nil {
+		f32.Add(p.strategySum, p.legalStrategyWeight)
+		for i := range p.legalStrategyWeight {
+			p.legalStrategyWeight[i] = 0
+		}
+	}
 
 	if discountPositiveRegret != 1.0 {
 		for i, x := range p.regretSum {
@@ -98,7 +152,43 @@ func (p *Policy) AddRegret(w float32, samplingQ, instantaneousRegrets []float32)
 }
 
 func (p *Policy) AddStrategyWeight(w float32) {
-	p.currentStrategyWeight += w
+	p.currentStrategyWeight += float32(float64(w) * p.weightScale)
+}
+
+func (p *Policy) AddLegalStrategyWeight(w float32, mask []bool) {
+	if mask == nil {
+		p.AddStrategyWeight(w)
+		return
+	}
+
+	if p.legalStrategyWeight == nil {
+		p.legalStrategyWeight = make([]float32, len(p.currentStrategy))
+	}
+
+	f32.AxpyUnitary(float32(float64(w)*p.weightScale), p.GetLegalStrategy(mask), p.legalStrategyWeight)
+}
+
+// Renormalize rescales the accumulated strategy weights to sum to 1, to
+// prevent them from overflowing. The average strategy is unchanged, and
+// strategy weight added later is scaled by the same factor.
+func (p *Policy) Renormalize() {
+	c := renormalizationFactor(float64(f32.Sum(p.strategySum)), p.weightScale)
+	if c == 1.0 {
+		return
+	}
+
+	f32.ScalUnitary(float32(c), p.strategySum)
+	p.currentStrategyWeight = float32(float64(p.currentStrategyWeight) * c)
+	if p.legalStrategyWeight != nil {
+		f32.ScalUnitary(float32(c), p.legalStrategyWeight)
+	}
+	p.weightScale *= c
+}
+
+// GetStrategyWeightScale returns the factor by which Renormalize has
+// scaled the strategy sums.
+func (p *Policy) GetStrategyWeightScale() float64 {
+	return p.weightScale
 }
 
 func (p *Policy) GetAverageStrategy() []float32 {
@@ -147,10 +237,22 @@ func (p *Policy) regretMatching() {
 	}
 }
 
+// extendedFormat marks the start of the encoding of a policy which has
+// been renormalized, or has pending legal strategy weight. Encodings of
+// other policies keep the original format, which starts with the current
+// strategy weight and so is never this NaN.
+const extendedFormat = 0xffffffff
+
 // UnmarshalBinary implements encoding.BinaryUnmarshaler.
 func (p *Policy) UnmarshalBinary(buf []byte) error {
-	nFloats := len(buf) / 4
-	nActions := (nFloats - 1) / 4
+	extended := len(buf) >= 8 && binary.LittleEndian.Uint32(buf) == extendedFormat
+	var nActions int
+	if extended {
+		nActions = int(binary.LittleEndian.Uint32(buf[4:]))
+		buf = buf[8:]
+	} else {
+		nActions = (len(buf)/4 - 1) / 4
+	}
 
 	p.currentStrategyWeight = decodeF32(buf[:4])
 	buf = buf[4:]
@@ -165,6 +267,18 @@ func (p *Policy) UnmarshalBinary(buf []byte) error {
 	buf = buf[4*nActions:]
 
 	p.baseline = decodeF32s(buf[:4*nActions])
+	buf = buf[4*nActions:]
+
+	p.weightScale = 1.0
+	p.legalStrategyWeight = nil
+	if extended {
+		p.weightScale = decodeF64(buf[:8])
+		buf = buf[8:]
+
+		if len(buf) > 0 {
+			p.legalStrategyWeight = decodeF32s(buf[:4*nActions])
+		}
+	}
 
 	return nil
 }
@@ -173,10 +287,21 @@ func (p *Policy) UnmarshalBinary(buf []byte) error {
 func (p *Policy) MarshalBinary() ([]byte, error) {
 	nActions := len(p.regretSum)
 	nBytes := 4 * (4*nActions + 1)
+	extended := p.weightScale != 1.0 || p.legalStrategyWeight != nil
+	if extended {
+		nBytes += 8 + 8 + 4*len(p.legalStrategyWeight)
+	}
 	result := make([]byte, nBytes)
 
-	putF32(result, p.currentStrategyWeight)
-	buf := result[4:]
+	buf := result
+	if extended {
+		binary.LittleEndian.PutUint32(buf, extendedFormat)
+		binary.LittleEndian.PutUint32(buf[4:], uint32(nActions))
+		buf = buf[8:]
+	}
+
+	putF32(buf, p.currentStrategyWeight)
+	buf = buf[4:]
 
 	putF32s(buf, p.currentStrategy)
 	buf = buf[4*nActions:]
@@ -188,6 +313,12 @@ func (p *Policy) MarshalBinary() ([]byte, error) {
 	buf = buf[4*nActions:]
 
 	putF32s(buf, p.baseline)
+	buf = buf[4*nActions:]
+
+	if extended {
+		putF64(buf, p.weightScale)
+		putF32s(buf[8:], p.legalStrategyWeight)
+	}
 
 	return result, nil
 }
@@ -228,6 +359,32 @@ func uniformDist(n int) []float32 {
 	return result
 }
 
+// restrict sets dst to the strategy restricted to the actions allowed
+// by mask and renormalized, or uniform over them if it has no support.
+func restrict(dst, strat []float32, mask []bool) {
+	var total float32
+	nLegal := 0
+	for i, x := range strat {
+		if mask[i] {
+			dst[i] = x
+			total += x
+			nLegal++
+		} else {
+			dst[i] = 0
+		}
+	}
+
+	if total > 0 {
+		f32.ScalUnitary(1.0/total, dst)
+	} else if nLegal > 0 {
+		for i := range dst {
+			if mask[i] {
+				dst[i] = 1.0 / float32(nLegal)
+			}
+		}
+	}
+}
+
 func makePositive(v []float32) {
 	for i := range v {
 		if v[i] < 0.0 {
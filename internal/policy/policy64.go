@@ -0,0 +1,339 @@
+package policy
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Policy64 is like Policy, but accumulates regrets and strategy weights
+// in float64. Over very long runs, the float32 sums of Policy grow so large
+// that new contributions are rounded away and the average strategy freezes.
+type Policy64 struct {
+	currentStrategy       []float32
+	currentStrategyWeight float64
+
+	baseline []float32
+
+	regretSum   []float64
+	strategySum []float64
+
+	// Strategy weight accumulated under a legal-action mask since the
+	// last call to NextStrategy. Allocated lazily.
+	legalStrategyWeight []float64
+	// Scratch space for GetLegalStrategy and GetStrategySum.
+	legalStrategy []float32
+	strategySum32 []float32
+
+	// Factor applied to all strategy weight, which Renormalize adjusts
+	// so that the average strategy is unchanged.
+	weightScale float64
+}
+
+// New64 returns a new Policy64 for a game node with the given number of actions.
+func New64(nActions int) *Policy64 {
+	return &Policy64{
+		currentStrategy: uniformDist(nActions),
+		baseline:        make([]float32, nActions),
+		regretSum:       make([]float64, nActions),
+		strategySum:     make([]float64, nActions),
+		weightScale:     1.0,
+	}
+}
+
+func (p *Policy64) GetStrategy() []float32 {
+	return p.currentStrategy
+}
+
+func (p *Policy64) GetLegalStrategy(mask []bool) []float32 {
+	if mask == nil {
+		return p.currentStrategy
+	}
+
+	if p.legalStrategy == nil {
+		p.legalStrategy = make([]float32, len(p.currentStrategy))
+	}
+
+	restrict(p.legalStrategy, p.currentStrategy, mask)
+	return p.legalStrategy
+}
+
+func (p *Policy64) SetStrategy(strat []float32) {
+	p.currentStrategy = strat
+}
+
+// SetStrategySum replaces the accumulated strategy sums, which are in the
+// same units as those returned by GetStrategySum.
+func (p *Policy64) SetStrategySum(sum []float32) {
+	for i, s := range sum {
+		p.strategySum[i] = float64(s)
+	}
+}
+
+func (p *Policy64) IsEmpty() bool {
+	for _, r := range p.regretSum {
+		if r != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *Policy64) NextStrategy(discountPositiveRegret, discountNegativeRegret, discountstrategySum float32) {
+	if discountstrategySum != 1.0 {
+		scal64(float64(discountstrategySum), p.strategySum)
+	}
+
+	for i, s := range p.currentStrategy {
+		p.strategySum[i] += p.currentStrategyWeight * float64(s)
+	}
+
+	if p.legalStrategyWeight != nil {
+		for i, w := range p.legalStrategyWeight {
+			p.strategySum[i] += w
+			p.legalStrategyWeight[i] = 0
+		}
+	}
+
+	for i, x := range p.regretSum {
+		if x > 0 {
+			p.regretSum[i] = x * float64(discountPositiveRegret)
+		} else if x < 0 {
+			p.regretSum[i] = x * float64(discountNegativeRegret)
+		}
+	}
+
+	p.regretMatching()
+	p.currentStrategyWeight = 0.0
+}
+
+func (p *Policy64) AddRegret(w float32, samplingQ, instantaneousRegrets []float32) {
+	for i, r := range instantaneousRegrets {
+		p.regretSum[i] += float64(w) * float64(r)
+	}
+}
+
+func (p *Policy64) AddStrategyWeight(w float32) {
+	p.currentStrategyWeight += float64(w) * p.weightScale
+}
+
+func (p *Policy64) AddLegalStrategyWeight(w float32, mask []bool) {
+	if mask == nil {
+		p.AddStrategyWeight(w)
+		return
+	}
+
+	if p.legalStrategyWeight == nil {
+		p.legalStrategyWeight = make([]float64, len(p.currentStrategy))
+	}
+
+	ww := float64(w) * p.weightScale
+	for i, s := range p.GetLegalStrategy(mask) {
+		p.legalStrategyWeight[i] += ww * float64(s)
+	}
+}
+
+// Renormalize rescales the accumulated strategy weights to sum to 1.
+// The average strategy is unchanged, and strategy weight added later is
+// scaled by the same factor.
+func (p *Policy64) Renormalize() {
+	c := renormalizationFactor(sum64(p.strategySum), p.weightScale)
+	if c == 1.0 {
+		return
+	}
+
+	scal64(c, p.strategySum)
+	p.currentStrategyWeight *= c
+	if p.legalStrategyWeight != nil {
+		scal64(c, p.legalStrategyWeight)
+	}
+	p.weightScale *= c
+}
+
+// GetStrategyWeightScale returns the factor by which Renormalize has
+// scaled the strategy sums.
+func (p *Policy64) GetStrategyWeightScale() float64 {
+	return p.weightScale
+}
+
+func (p *Policy64) GetAverageStrategy() []float32 {
+	avgStrat := make([]float32, len(p.strategySum))
+
+	total := sum64(p.strategySum)
+	if total > 0 {
+		for i, s := range p.strategySum {
+			avgStrat[i] = float32(s / total)
+		}
+	} else {
+		for i := range avgStrat {
+			avgStrat[i] = 1.0 / float32(len(avgStrat))
+		}
+	}
+
+	return avgStrat
+}
+
+// GetStrategySum returns the strategy sums rounded to float32.
+// The returned slice is reused between calls.
+func (p *Policy64) GetStrategySum() []float32 {
+	if p.strategySum32 == nil {
+		p.strategySum32 = make([]float32, len(p.strategySum))
+	}
+
+	for i, s := range p.strategySum {
+		p.strategySum32[i] = float32(s)
+	}
+
+	return p.strategySum32
+}
+
+func (p *Policy64) GetBaseline() []float32 {
+	return p.baseline
+}
+
+func (p *Policy64) UpdateBaseline(w float32, action int, value float32) {
+	v := p.baseline[action] + w*(value-p.baseline[action])
+	p.baseline[action] *= (1 - decayAlpha)
+	p.baseline[action] += decayAlpha * v
+}
+
+func (p *Policy64) NumActions() int {
+	return len(p.regretSum)
+}
+
+func (p *Policy64) regretMatching() {
+	var total float64
+	for _, x := range p.regretSum {
+		if x > 0 {
+			total += x
+		}
+	}
+
+	if total > 0 {
+		for i, x := range p.regretSum {
+			if x > 0 {
+				p.currentStrategy[i] = float32(x / total)
+			} else {
+				p.currentStrategy[i] = 0
+			}
+		}
+	} else {
+		for i := range p.currentStrategy {
+			p.currentStrategy[i] = 1.0 / float32(len(p.currentStrategy))
+		}
+	}
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *Policy64) UnmarshalBinary(buf []byte) error {
+	nFloats := len(buf) / 8
+	nActions := (nFloats - 2) / 5
+
+	p.currentStrategyWeight = decodeF64(buf[:8])
+	p.weightScale = decodeF64(buf[8:16])
+	buf = buf[16:]
+
+	p.currentStrategy = toF32s(decodeF64s(buf[:8*nActions]))
+	buf = buf[8*nActions:]
+
+	p.regretSum = decodeF64s(buf[:8*nActions])
+	buf = buf[8*nActions:]
+
+	p.strategySum = decodeF64s(buf[:8*nActions])
+	buf = buf[8*nActions:]
+
+	p.baseline = toF32s(decodeF64s(buf[:8*nActions]))
+	buf = buf[8*nActions:]
+
+	p.legalStrategyWeight = nil
+	if legal := decodeF64s(buf[:8*nActions]); sum64(legal) != 0 {
+		p.legalStrategyWeight = legal
+	}
+
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p *Policy64) MarshalBinary() ([]byte, error) {
+	nActions := len(p.regretSum)
+	nBytes := 8 * (5*nActions + 2)
+	result := make([]byte, nBytes)
+
+	putF64(result, p.currentStrategyWeight)
+	putF64(result[8:], p.weightScale)
+	buf := result[16:]
+
+	putF64s(buf, toF64s(p.currentStrategy))
+	buf = buf[8*nActions:]
+
+	putF64s(buf, p.regretSum)
+	buf = buf[8*nActions:]
+
+	putF64s(buf, p.strategySum)
+	buf = buf[8*nActions:]
+
+	putF64s(buf, toF64s(p.baseline))
+	buf = buf[8*nActions:]
+
+	// Strategy weight added under a mask since the last NextStrategy,
+	// which is zero if there is none.
+	putF64s(buf, p.legalStrategyWeight)
+
+	return result, nil
+}
+
+func putF64(buf []byte, x float64) {
+	binary.LittleEndian.PutUint64(buf, math.Float64bits(x))
+}
+
+func decodeF64(buf []byte) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(buf[:8]))
+}
+
+func putF64s(buf []byte, v []float64) {
+	for i, x := range v {
+		putF64(buf[8*i:], x)
+	}
+}
+
+func decodeF64s(buf []byte) []float64 {
+	v := make([]float64, len(buf)/8)
+	for i := range v {
+		v[i] = decodeF64(buf[8*i:])
+	}
+
+	return v
+}
+
+func toF64s(v []float32) []float64 {
+	result := make([]float64, len(v))
+	for i, x := range v {
+		result[i] = float64(x)
+	}
+
+	return result
+}
+
+func toF32s(v []float64) []float32 {
+	result := make([]float32, len(v))
+	for i, x := range v {
+		result[i] = float32(x)
+	}
+
+	return result
+}
+
+func sum64(v []float64) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x
+	}
+
+	return sum
+}
+
+func scal64(alpha float64, v []float64) {
+	for i := range v {
+		v[i] *= alpha
+	}
+}
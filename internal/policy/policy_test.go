@@ -0,0 +1,212 @@
+package policy
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestGetLegalStrategy(t *testing.T) {
+	p := New(3)
+	p.AddRegret(1.0, nil, []float32{1.0, 3.0, -1.0})
+	p.NextStrategy(1.0, 1.0, 1.0)
+
+	mask := []bool{true, false, true}
+	strat := p.GetLegalStrategy(mask)
+	if strat[0] != 1.0 || strat[1] != 0 || strat[2] != 0 {
+		t.Errorf("expected all weight on the only legal positive regret, got %v", strat)
+	}
+
+	mask = []bool{false, false, true}
+	strat = p.GetLegalStrategy(mask)
+	if strat[2] != 1.0 {
+		t.Errorf("expected uniform over legal actions, got %v", strat)
+	}
+}
+
+func TestAddLegalStrategyWeight(t *testing.T) {
+	p := New(2)
+	p.AddLegalStrategyWeight(1.0, []bool{false, true})
+	p.NextStrategy(1.0, 1.0, 1.0)
+
+	avg := p.GetAverageStrategy()
+	if avg[0] != 0 || avg[1] != 1.0 {
+		t.Errorf("illegal action contributed to the average strategy: %v", avg)
+	}
+}
+
+// accumulator is implemented by both Policy and Policy64.
+type accumulator interface {
+	AddRegret(w float32, samplingQ, instantaneousRegrets []float32)
+	AddStrategyWeight(w float32)
+	AddLegalStrategyWeight(w float32, mask []bool)
+	NextStrategy(discountPositiveRegret, discountNegativeRegret, discountstrategySum float32)
+	GetAverageStrategy() []float32
+	Renormalize()
+}
+
+func TestRenormalize(t *testing.T) {
+	for name, newPolicy := range map[string]func() accumulator{
+		"Policy":   func() accumulator { return New(3) },
+		"Policy64": func() accumulator { return New64(3) },
+	} {
+		p, q := newPolicy(), newPolicy()
+		for i := 0; i < 100; i++ {
+			if i%10 == 0 {
+				p.Renormalize()
+				checkClose(t, name, p.GetAverageStrategy(), q.GetAverageStrategy())
+			}
+
+			for _, x := range []accumulator{p, q} {
+				x.AddRegret(1.0, nil, []float32{float32(i % 3), 1.0, -1.0})
+				x.AddStrategyWeight(float32(i + 1))
+				x.AddLegalStrategyWeight(2.0, []bool{true, false, true})
+				x.NextStrategy(1.0, 1.0, 1.0)
+			}
+		}
+
+		checkClose(t, name, p.GetAverageStrategy(), q.GetAverageStrategy())
+	}
+}
+
+func checkClose(t *testing.T, name string, got, want []float32) {
+	for i := range want {
+		if math.Abs(float64(got[i]-want[i])) > 1e-5 {
+			t.Errorf("%s: renormalization changed the average strategy: got %v, want %v", name, got, want)
+			return
+		}
+	}
+}
+
+func TestRenormalizePreventsOverflow(t *testing.T) {
+	// Sampling weights of 1/sampleProb can be very large.
+	p := New(2)
+	for i := 0; i < 10000; i++ {
+		if i%100 == 0 {
+			p.Renormalize()
+		}
+
+		p.AddRegret(1.0, nil, []float32{1.0, 0.0})
+		p.AddStrategyWeight(1e36)
+		p.NextStrategy(1.0, 1.0, 1.0)
+	}
+
+	avg := p.GetAverageStrategy()
+	if math.IsNaN(float64(avg[0])) || avg[0] < 0.99 {
+		t.Errorf("expected average strategy to converge to the first action, got %v", avg)
+	}
+}
+
+// averageAfterLongRun puts a large initial weight on the uniform strategy,
+// and then many small weights on the first action.
+func averageAfterLongRun(p accumulator, n int) float32 {
+	p.AddStrategyWeight(1e8)
+	p.NextStrategy(1.0, 1.0, 1.0)
+	p.AddRegret(1.0, nil, []float32{1.0, 0.0})
+	for i := 0; i < n; i++ {
+		p.AddStrategyWeight(1.0)
+		p.NextStrategy(1.0, 1.0, 1.0)
+	}
+
+	return p.GetAverageStrategy()[0]
+}
+
+func TestPolicy64LongRun(t *testing.T) {
+	const n = 1000000
+	want := (0.5e8 + n) / (1e8 + n)
+
+	got := averageAfterLongRun(New64(2), n)
+	if math.Abs(float64(got)-want) > 1e-6 {
+		t.Errorf("expected average strategy %v, got %v", want, got)
+	}
+
+	// The float32 sums are too large for the new weights to register.
+	got32 := averageAfterLongRun(New(2), n)
+	if math.Abs(float64(got32)-want) <= math.Abs(float64(got)-want) {
+		t.Errorf("expected float32 policy (%v) to be less accurate than float64 (%v)", got32, got)
+	}
+}
+
+func TestRenormalizeScaleUnderflow(t *testing.T) {
+	for name, newPolicy := range map[string]func() accumulator{
+		"Policy":   func() accumulator { return New(2) },
+		"Policy64": func() accumulator { return New64(2) },
+	} {
+		// The weight scale falls far below the smallest normal float32.
+		p := newPolicy()
+		for _, regrets := range [][]float32{{1.0, 0.0}, {-2.0, 1.0}} {
+			p.AddRegret(1.0, nil, regrets)
+			for i := 0; i < 10000; i++ {
+				p.AddStrategyWeight(1e38)
+				p.NextStrategy(1.0, 1.0, 1.0)
+				p.Renormalize()
+			}
+		}
+
+		if avg := p.GetAverageStrategy(); math.Abs(float64(avg[0])-0.5) > 1e-3 {
+			t.Errorf("%s: expected both actions to have weight 0.5, got %v", name, avg)
+		}
+	}
+}
+
+func TestMarshalRenormalized(t *testing.T) {
+	p := New(2)
+	p.AddStrategyWeight(4.0)
+	p.NextStrategy(1.0, 1.0, 1.0)
+	p.Renormalize()
+	p.AddLegalStrategyWeight(2.0, []bool{false, true})
+
+	buf, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var q Policy
+	if err := q.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if q.NumActions() != 2 || q.weightScale != 0.25 || !reflect.DeepEqual(q.legalStrategyWeight, []float32{0, 0.5}) {
+		t.Errorf("expected 2 actions with weight scale 0.25 and legal weight [0 0.5], got %d with %v and %v",
+			q.NumActions(), q.weightScale, q.legalStrategyWeight)
+	}
+
+	// Policies that were never renormalized keep the original format.
+	buf, _ = New(2).MarshalBinary()
+	if len(buf) != 4*(4*2+1) {
+		t.Errorf("unexpected encoded size: %d", len(buf))
+	}
+
+	if err := q.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if q.NumActions() != 2 || q.weightScale != 1.0 || q.legalStrategyWeight != nil {
+		t.Errorf("expected 2 actions with weight scale 1, got %d with %v", q.NumActions(), q.weightScale)
+	}
+}
+
+func TestMarshalPolicy64(t *testing.T) {
+	p := New64(3)
+	p.AddRegret(1.0, nil, []float32{1.0, 2.0, -1.0})
+	p.AddStrategyWeight(3.0)
+	p.NextStrategy(1.0, 1.0, 1.0)
+	p.Renormalize()
+	p.AddLegalStrategyWeight(1.0, []bool{true, false, true})
+
+	buf, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var q Policy64
+	if err := q.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(p.regretSum, q.regretSum) || !reflect.DeepEqual(p.strategySum, q.strategySum) ||
+		!reflect.DeepEqual(p.currentStrategy, q.currentStrategy) || p.weightScale != q.weightScale ||
+		!reflect.DeepEqual(p.legalStrategyWeight, q.legalStrategyWeight) {
+		t.Errorf("round trip mismatch: %+v != %+v", p, q)
+	}
+}
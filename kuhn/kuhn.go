@@ -0,0 +1,306 @@
+// Package kuhn implements Kuhn poker, a small reference game with a known
+// equilibrium value, for testing CFR implementations.
+//
+// Each player antes 1 chip and is dealt one card from a three-card deck.
+// Player 0 may pass or bet 1 chip. After a pass, player 1 may pass
+// (showdown) or bet, after which player 0 may fold or call. After a bet,
+// player 1 may fold or call. The game value for player 0 is -1/18.
+package kuhn
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math/rand"
+
+	"github.com/tam0705/go-cfr"
+)
+
+const (
+	NODE_CHANCE = -1
+	NODE_P0     = 0
+	NODE_P1     = 1
+)
+
+const (
+	ACTION_PASS byte = 'p'
+	ACTION_BET  byte = 'b'
+)
+
+// Card is a card in the three-card Kuhn poker deck.
+type Card byte
+
+const (
+	JACK  Card = 'J'
+	QUEEN Card = 'Q'
+	KING  Card = 'K'
+)
+
+// String implements fmt.Stringer.
+func (c Card) String() string {
+	return string([]byte{byte(c)})
+}
+
+// rank returns the relative strength of a card.
+func (c Card) rank() int {
+	switch c {
+	case JACK:
+		return 0
+	case QUEEN:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// DEALS are all possible deals of a card to each player, which are equally likely.
+var DEALS = [6][2]Card{
+	{JACK, QUEEN}, {JACK, KING},
+	{QUEEN, JACK}, {QUEEN, KING},
+	{KING, JACK}, {KING, QUEEN},
+}
+
+var dealProbabilities = []float64{1.0 / 6, 1.0 / 6, 1.0 / 6, 1.0 / 6, 1.0 / 6, 1.0 / 6}
+
+// GAME_VALUE is the expected value of the game for player 0 at equilibrium.
+const GAME_VALUE = -1.0 / 18
+
+// KuhnNode implements cfr.GameTreeNode for Kuhn poker.
+type KuhnNode struct {
+	parent        *KuhnNode
+	player        int
+	children      []KuhnNode
+	probabilities []float64
+	history       string
+	cards         [2]Card
+}
+
+// NewGame returns the root of a new Kuhn poker game tree.
+func NewGame() *KuhnNode {
+	return &KuhnNode{player: NODE_CHANCE}
+}
+
+// String implements fmt.Stringer.
+func (k KuhnNode) String() string {
+	if k.parent == nil {
+		return "Deal"
+	}
+
+	return fmt.Sprintf("Player %v's turn. Cards: %v%v History: %s",
+		k.player, k.cards[0], k.cards[1], k.history)
+}
+
+// GetNode implements cfr.GameTreeNode. The history is given as both
+// players' cards followed by the actions, e.g. "KQpb".
+func (k *KuhnNode) GetNode(history string) cfr.GameTreeNode {
+	if k.parent != nil || len(history) < 2 {
+		return nil
+	}
+
+	for i, deal := range DEALS {
+		if deal[0] != Card(history[0]) || deal[1] != Card(history[1]) {
+			continue
+		}
+
+		var node cfr.GameTreeNode = k.GetChild(i)
+		for _, action := range []byte(history[2:]) {
+			j := 0
+			if action == ACTION_BET {
+				j = 1
+			} else if action != ACTION_PASS {
+				return nil
+			}
+
+			if node.NumChildren() == 0 {
+				return nil
+			}
+			node = node.GetChild(j)
+		}
+
+		return node
+	}
+
+	return nil
+}
+
+// Close implements cfr.GameTreeNode.
+func (k *KuhnNode) Close() {
+	k.children = nil
+	k.probabilities = nil
+}
+
+// Type implements cfr.GameTreeNode.
+func (k *KuhnNode) Type() cfr.NodeType {
+	if k.IsTerminal() {
+		return cfr.TerminalNodeType
+	} else if k.player == NODE_CHANCE {
+		return cfr.ChanceNodeType
+	}
+
+	return cfr.PlayerNodeType
+}
+
+// IsTerminal returns whether the game is over.
+func (k *KuhnNode) IsTerminal() bool {
+	switch k.history {
+	case "pp", "bp", "bb", "pbp", "pbb":
+		return true
+	}
+
+	return false
+}
+
+// NumChildren implements cfr.GameTreeNode.
+func (k *KuhnNode) NumChildren() int {
+	if k.children == nil {
+		k.buildChildren()
+	}
+
+	return len(k.children)
+}
+
+// GetChild implements cfr.GameTreeNode.
+func (k *KuhnNode) GetChild(i int) cfr.GameTreeNode {
+	if k.children == nil {
+		k.buildChildren()
+	}
+
+	return &k.children[i]
+}
+
+// Parent implements cfr.GameTreeNode.
+func (k *KuhnNode) Parent() cfr.GameTreeNode {
+	if k.parent == nil {
+		return nil
+	}
+
+	return k.parent
+}
+
+// GetChildProbability implements cfr.GameTreeNode.
+func (k *KuhnNode) GetChildProbability(i int) float64 {
+	if k.children == nil {
+		k.buildChildren()
+	}
+	if k.probabilities == nil {
+		return 0.0
+	}
+
+	return k.probabilities[i]
+}
+
+// SampleChild implements cfr.GameTreeNode.
+func (k *KuhnNode) SampleChild() (cfr.GameTreeNode, float64) {
+	i := rand.Intn(k.NumChildren())
+	return k.GetChild(i), k.GetChildProbability(i)
+}
+
+// Player implements cfr.GameTreeNode.
+func (k *KuhnNode) Player() int {
+	return k.player
+}
+
+// Utility implements cfr.GameTreeNode.
+func (k *KuhnNode) Utility(player int) float64 {
+	var u float64
+	switch k.history {
+	case "bp":
+		u = 1.0 // Player 1 folds.
+	case "pbp":
+		u = -1.0 // Player 0 folds.
+	case "pp":
+		u = k.showdown()
+	case "bb", "pbb":
+		u = 2 * k.showdown()
+	}
+
+	if player == NODE_P1 {
+		return -u
+	}
+
+	return u
+}
+
+// showdown returns 1 if player 0 has the higher card, and -1 otherwise.
+func (k *KuhnNode) showdown() float64 {
+	if k.cards[0].rank() > k.cards[1].rank() {
+		return 1.0
+	}
+
+	return -1.0
+}
+
+// InfoSet implements cfr.GameTreeNode.
+func (k *KuhnNode) InfoSet(player int) cfr.InfoSet {
+	return &InfoSet{
+		Card:    k.cards[player],
+		History: k.history,
+	}
+}
+
+// InfoSetKey implements cfr.GameTreeNode.
+func (k *KuhnNode) InfoSetKey(player int) []byte {
+	return k.InfoSet(player).Key()
+}
+
+// LegalActions implements cfr.GameTreeNode. All actions are always legal.
+func (k *KuhnNode) LegalActions() []bool {
+	return nil
+}
+
+func (k *KuhnNode) buildChildren() {
+	if k.IsTerminal() {
+		return
+	}
+
+	if k.player == NODE_CHANCE {
+		k.children = make([]KuhnNode, len(DEALS))
+		for i, deal := range DEALS {
+			k.children[i] = KuhnNode{parent: k, player: NODE_P0, cards: deal}
+		}
+
+		k.probabilities = dealProbabilities
+		return
+	}
+
+	k.children = make([]KuhnNode, 2)
+	for i, action := range []byte{ACTION_PASS, ACTION_BET} {
+		k.children[i] = KuhnNode{
+			parent:  k,
+			player:  1 - k.player,
+			history: k.history + string([]byte{action}),
+			cards:   k.cards,
+		}
+	}
+}
+
+// InfoSet is the information available to a player: their own card,
+// and the actions taken so far.
+type InfoSet struct {
+	Card    Card
+	History string
+}
+
+// Key implements cfr.InfoSet.
+func (is *InfoSet) Key() []byte {
+	return append([]byte{byte(is.Card)}, is.History...)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (is *InfoSet) MarshalBinary() ([]byte, error) {
+	return is.Key(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (is *InfoSet) UnmarshalBinary(buf []byte) error {
+	if len(buf) == 0 {
+		return fmt.Errorf("kuhn: empty InfoSet")
+	}
+
+	is.Card = Card(buf[0])
+	is.History = string(buf[1:])
+	return nil
+}
+
+func init() {
+	gob.Register(&InfoSet{})
+}
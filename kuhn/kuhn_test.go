@@ -0,0 +1,233 @@
+package kuhn
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+	"github.com/tam0705/go-cfr/internal/cfrtest"
+	"github.com/tam0705/go-cfr/sampling"
+	"github.com/tam0705/go-cfr/tree"
+)
+
+func TestGameTree(t *testing.T) {
+	root := NewGame()
+	if n := tree.CountNodes(root); n != 1+6*9 {
+		t.Errorf("expected 55 nodes, got %d", n)
+	}
+
+	if n := tree.CountTerminalNodes(root); n != 6*5 {
+		t.Errorf("expected 30 terminal nodes, got %d", n)
+	}
+
+	// 3 cards x 2 histories for each player.
+	if n := tree.CountInfoSets(root); n != 12 {
+		t.Errorf("expected 12 infosets, got %d", n)
+	}
+
+	if problems := tree.Validate(root); len(problems) > 0 {
+		t.Errorf("invalid game tree: %v", problems)
+	}
+}
+
+func TestGetNode(t *testing.T) {
+	node := NewGame().GetNode("KQpb")
+	if node == nil {
+		t.Fatal("node KQpb not found")
+	}
+
+	if key := string(node.InfoSetKey(node.Player())); node.Player() != NODE_P0 || key != "Kpb" {
+		t.Errorf("expected player 0 with infoset Kpb, got player %d with %s", node.Player(), key)
+	}
+
+	if u := node.GetChild(1).Utility(NODE_P0); u != 2 {
+		t.Errorf("expected player 0 to win 2 after calling with the King, got %v", u)
+	}
+
+	if NewGame().GetNode("KKp") != nil {
+		t.Error("expected nil for an impossible deal")
+	}
+}
+
+func TestInfoSetMarshal(t *testing.T) {
+	is := &InfoSet{Card: QUEEN, History: "pb"}
+	buf, err := is.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded InfoSet
+	if err := decoded.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded != *is {
+		t.Errorf("expected %v, got %v", *is, decoded)
+	}
+}
+
+func TestSamplers(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		sampler func(cfr.StrategyProfile) cfr.Sampler
+		nIter   int
+	}{
+		{"ExternalSampler", func(cfr.StrategyProfile) cfr.Sampler {
+			return sampling.NewExternalSampler()
+		}, 20000},
+		{"OutcomeSampler", func(cfr.StrategyProfile) cfr.Sampler {
+			return sampling.NewOutcomeSampler(0.6)
+		}, 200000},
+		{"MultiOutcomeSampler", func(cfr.StrategyProfile) cfr.Sampler {
+			return sampling.NewMultiOutcomeSampler(1, 0.6)
+		}, 200000},
+		{"RobustSampler", func(cfr.StrategyProfile) cfr.Sampler {
+			return sampling.NewRobustSampler(1)
+		}, 200000},
+		{"AverageStrategySampler", func(cfr.StrategyProfile) cfr.Sampler {
+			return sampling.NewAverageStrategySampler(sampling.AverageStrategyParams{Epsilon: 0.05, Tau: 1000, Beta: 1e6})
+		}, 50000},
+	} {
+		profile := cfr.NewPolicyTable(cfr.DiscountParams{})
+		cfrtest.Train(profile, NewGame(), tc.sampler(profile), tc.nIter)
+		cfrtest.CheckGameValue(t, tc.name, NewGame(), profile, GAME_VALUE, 0.01)
+	}
+}
+
+func TestChanceSamplers(t *testing.T) {
+	for name, cs := range map[string]cfr.ChanceSampler{
+		"StratifiedChanceSampler": sampling.NewStratifiedChanceSampler(3),
+		// Deals with the King are sampled more often.
+		"ImportanceChanceSampler": sampling.NewImportanceChanceSampler(func(node cfr.GameTreeNode, i int) float64 {
+			return float64(i + 1)
+		}),
+	} {
+		profile := cfr.NewPolicyTable(cfr.DiscountParams{})
+		opt := cfr.NewMCCFR(profile, sampling.NewExternalSampler())
+		opt.SetChanceSampler(cs)
+		root := NewGame()
+		for i := 0; i < 20000; i++ {
+			opt.Run(root)
+			profile.Update()
+		}
+
+		cfrtest.CheckGameValue(t, name, NewGame(), profile, GAME_VALUE, 0.01)
+	}
+}
+
+func TestDiscountParams(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		params cfr.DiscountParams
+	}{
+		{"CFR", cfr.DiscountParams{}},
+		{"CFR+", cfr.DiscountParams{UseRegretMatchingPlus: true, LinearWeighting: true}},
+		{"LinearCFR", cfr.DiscountParams{LinearWeighting: true}},
+		{"DiscountedCFR", cfr.DiscountParams{DiscountAlpha: 1.5, DiscountBeta: 0.5, DiscountGamma: 2}},
+	} {
+		profile := cfr.NewPolicyTable(tc.params)
+		cfrtest.Train(profile, NewGame(), sampling.NewExternalSampler(), 20000)
+		cfrtest.CheckGameValue(t, tc.name, NewGame(), profile, GAME_VALUE, 0.01)
+	}
+}
+
+// recordingSampler records the SampleContext of each node it samples,
+// by its full history (e.g. "KQpb"), and always returns q.
+type recordingSampler struct {
+	q        []float32
+	contexts map[string]cfr.SampleContext
+}
+
+func (s *recordingSampler) SampleWithContext(node cfr.GameTreeNode, policy cfr.NodePolicy, sc cfr.SampleContext) []float32 {
+	k := node.(*KuhnNode)
+	s.contexts[k.cards[0].String()+k.cards[1].String()+k.history] = sc
+	return s.q
+}
+
+func checkContext(t *testing.T, history string, got, want cfr.SampleContext) {
+	near := func(x, y float32) bool { return math.Abs(float64(x-y)) < 1e-6 }
+	if got.Iter != want.Iter || got.Depth != want.Depth || got.TraversingPlayer != want.TraversingPlayer ||
+		!near(got.SampleProb, want.SampleProb) || !near(got.PlayerReach, want.PlayerReach) ||
+		!near(got.OpponentReach, want.OpponentReach) {
+		t.Errorf("%s: expected context %+v, got %+v", history, want, got)
+	}
+}
+
+func TestSampleContext(t *testing.T) {
+	profile := cfr.NewPolicyTable(cfr.DiscountParams{})
+	sampler := &recordingSampler{q: []float32{0.5, 1.0}}
+	opt := cfr.NewContextMCCFR(profile, sampler)
+	opt.SetChanceSampler(sampling.NewEnumeratingChanceSampler())
+	root := NewGame()
+
+	// Player 0 traverses first. Each deal has chance weight 1/6, which
+	// is included in the sample probability.
+	sampler.contexts = make(map[string]cfr.SampleContext)
+	opt.Run(root)
+	for _, deal := range DEALS {
+		history := deal[0].String() + deal[1].String()
+		checkContext(t, history, sampler.contexts[history], cfr.SampleContext{
+			Iter: 1, Depth: 1, SampleProb: 6, PlayerReach: 1, OpponentReach: 1.0 / 6})
+	}
+
+	// JQ is the first deal, so player 0 still plays uniformly with the Jack.
+	// Player 1's bet is only reached if it was sampled.
+	if sc, ok := sampler.contexts["JQpb"]; ok {
+		checkContext(t, "JQpb", sc, cfr.SampleContext{
+			Iter: 1, Depth: 3, SampleProb: 3, PlayerReach: 0.5, OpponentReach: 1.0 / 12})
+	}
+
+	profile.Update()
+
+	// Player 1 traverses next, with player 0 playing its updated strategy.
+	sampler.contexts = make(map[string]cfr.SampleContext)
+	opt.Run(root)
+	nReached := 0
+	for history, sc := range sampler.contexts {
+		if len(history) != 3 {
+			continue
+		}
+
+		nReached++
+		policy, ok := profile.GetPolicyByKey(history[:1])
+		if !ok {
+			t.Fatalf("no policy for player 0 with %s", history[:1])
+		}
+
+		action := 0
+		if history[2] == ACTION_BET {
+			action = 1
+		}
+
+		checkContext(t, history, sc, cfr.SampleContext{
+			Iter: 2, Depth: 2, TraversingPlayer: 1, SampleProb: 6, PlayerReach: 1,
+			OpponentReach: policy.GetStrategy()[action] / 6})
+	}
+
+	if nReached != len(DEALS) {
+		t.Errorf("expected one player 1 node for each deal, got %d", nReached)
+	}
+}
+
+func TestCompiledCFR(t *testing.T) {
+	compiled, err := tree.Compile(context.Background(), NewGame(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := tree.NewCompiledCFR(compiled, cfr.DiscountParams{LinearWeighting: true})
+	for i := 0; i < 2000; i++ {
+		c.Run()
+	}
+
+	// Player 0 never bets with the Queen at equilibrium.
+	id, ok := compiled.InfoSetID([]byte("Q"))
+	if !ok {
+		t.Fatal("infoset Q not found")
+	}
+
+	if avgStrat := c.GetAverageStrategy(id); avgStrat[1] > 0.01 {
+		t.Errorf("expected player 0 to pass with the Queen, got %v", avgStrat)
+	}
+}
@@ -0,0 +1,432 @@
+// Package leduc implements Leduc hold'em, a standard mid-size benchmark
+// game in which exploitability can still be computed exactly.
+//
+// The deck has two each of the Jack, Queen and King. Each player antes 1 chip
+// and is dealt one private card. There are two betting rounds, with raises of
+// 2 chips in the first round and 4 chips in the second, and at most two raises
+// per round. Player 0 acts first in each round. A public card is dealt between
+// the rounds. At showdown, a player whose card pairs the public card wins,
+// and otherwise the higher card wins.
+package leduc
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math/rand"
+
+	"github.com/tam0705/go-cfr"
+)
+
+const (
+	NODE_CHANCE = -1
+	NODE_P0     = 0
+	NODE_P1     = 1
+)
+
+// Actions, in the order of the children of each player node.
+// Only legal actions have a child.
+const (
+	ACTION_FOLD  byte = 'f'
+	ACTION_CALL  byte = 'c' // Also a check, if there is no bet to call.
+	ACTION_RAISE byte = 'r'
+)
+
+var ACTIONS = [3]byte{ACTION_FOLD, ACTION_CALL, ACTION_RAISE}
+
+// Card is the rank of a card in the Leduc deck.
+type Card byte
+
+const (
+	NO_CARD Card = 0
+	JACK    Card = 'J'
+	QUEEN   Card = 'Q'
+	KING    Card = 'K'
+)
+
+// CARDS are the ranks in the deck, from lowest to highest.
+var CARDS = [3]Card{JACK, QUEEN, KING}
+
+// String implements fmt.Stringer.
+func (c Card) String() string {
+	if c == NO_CARD {
+		return "?"
+	}
+
+	return string([]byte{byte(c)})
+}
+
+const (
+	ANTE       = 1
+	MAX_RAISES = 2
+	N_PER_RANK = 2
+	N_ROUNDS   = 2
+	N_ACTIONS  = len(ACTIONS)
+	N_PLAYERS  = 2
+	NOT_FOLDED = -1
+	ROUND_SEP  = '/'
+)
+
+// GAME_VALUE is approximately the expected value of the game for player 0 at equilibrium.
+const GAME_VALUE = -0.0856
+
+// RAISE_SIZE is the size of a raise in each betting round.
+var RAISE_SIZE = [N_ROUNDS]int{2, 4}
+
+// LeducNode implements cfr.GameTreeNode for Leduc hold'em.
+type LeducNode struct {
+	parent        *LeducNode
+	player        int
+	children      []LeducNode
+	probabilities []float64
+
+	// path is the label of every edge from the root: dealt cards and actions.
+	path string
+	// history is the betting history, with rounds separated by ROUND_SEP.
+	history string
+
+	cards  [N_PLAYERS]Card
+	public Card
+
+	round   int
+	raises  int
+	pot     [N_PLAYERS]int
+	folder  int
+	showing bool
+}
+
+// NewGame returns the root of a new Leduc hold'em game tree.
+func NewGame() *LeducNode {
+	return &LeducNode{
+		player: NODE_CHANCE,
+		pot:    [N_PLAYERS]int{ANTE, ANTE},
+		folder: NOT_FOLDED,
+	}
+}
+
+// String implements fmt.Stringer.
+func (k LeducNode) String() string {
+	return fmt.Sprintf("Player %v's turn. Cards: %v%v Public: %v History: %s",
+		k.player, k.cards[0], k.cards[1], k.public, k.history)
+}
+
+// GetNode implements cfr.GameTreeNode. The history is the sequence of
+// dealt cards and actions from this node, e.g. "KQrcJcr" for player 0
+// holding the King, player 1 the Queen, and the Jack as public card.
+func (k *LeducNode) GetNode(history string) cfr.GameTreeNode {
+	node := k
+	for _, label := range []byte(history) {
+		var next *LeducNode
+		for i := 0; i < node.NumChildren(); i++ {
+			child := &node.children[i]
+			if child.path[len(child.path)-1] == label {
+				next = child
+				break
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+
+	return node
+}
+
+// Close implements cfr.GameTreeNode.
+func (k *LeducNode) Close() {
+	k.children = nil
+	k.probabilities = nil
+}
+
+// Type implements cfr.GameTreeNode.
+func (k *LeducNode) Type() cfr.NodeType {
+	if k.IsTerminal() {
+		return cfr.TerminalNodeType
+	} else if k.player == NODE_CHANCE {
+		return cfr.ChanceNodeType
+	}
+
+	return cfr.PlayerNodeType
+}
+
+// IsTerminal returns whether the game is over.
+func (k *LeducNode) IsTerminal() bool {
+	return k.folder != NOT_FOLDED || k.showing
+}
+
+// NumChildren implements cfr.GameTreeNode.
+func (k *LeducNode) NumChildren() int {
+	if k.children == nil {
+		k.buildChildren()
+	}
+
+	return len(k.children)
+}
+
+// GetChild implements cfr.GameTreeNode.
+func (k *LeducNode) GetChild(i int) cfr.GameTreeNode {
+	if k.children == nil {
+		k.buildChildren()
+	}
+
+	return &k.children[i]
+}
+
+// Parent implements cfr.GameTreeNode.
+func (k *LeducNode) Parent() cfr.GameTreeNode {
+	if k.parent == nil {
+		return nil
+	}
+
+	return k.parent
+}
+
+// GetChildProbability implements cfr.GameTreeNode.
+func (k *LeducNode) GetChildProbability(i int) float64 {
+	if k.children == nil {
+		k.buildChildren()
+	}
+	if k.probabilities == nil {
+		return 0.0
+	}
+
+	return k.probabilities[i]
+}
+
+// SampleChild implements cfr.GameTreeNode.
+func (k *LeducNode) SampleChild() (cfr.GameTreeNode, float64) {
+	nChildren := k.NumChildren()
+	if k.probabilities == nil {
+		i := rand.Intn(nChildren)
+		return k.GetChild(i), 0.0
+	}
+
+	x := rand.Float64()
+	var cumProb float64
+	for i, p := range k.probabilities {
+		cumProb += p
+		if cumProb > x {
+			return k.GetChild(i), p
+		}
+	}
+
+	return k.GetChild(nChildren - 1), k.probabilities[nChildren-1]
+}
+
+// Player implements cfr.GameTreeNode.
+func (k *LeducNode) Player() int {
+	return k.player
+}
+
+// Utility implements cfr.GameTreeNode.
+func (k *LeducNode) Utility(player int) float64 {
+	opponent := 1 - player
+	if k.folder == player {
+		return -float64(k.pot[player])
+	} else if k.folder == opponent {
+		return float64(k.pot[opponent])
+	}
+
+	switch diff := handRank(k.cards[player], k.public) - handRank(k.cards[opponent], k.public); {
+	case diff > 0:
+		return float64(k.pot[opponent])
+	case diff < 0:
+		return -float64(k.pot[player])
+	}
+
+	return 0.0
+}
+
+// handRank returns the relative strength of a private card given the
+// public card: a pair beats any other card.
+func handRank(card, public Card) int {
+	if card == public {
+		return len(CARDS)
+	}
+
+	for i, c := range CARDS {
+		if c == card {
+			return i
+		}
+	}
+
+	panic(fmt.Errorf("leduc: invalid card %v", card))
+}
+
+// InfoSet implements cfr.GameTreeNode.
+func (k *LeducNode) InfoSet(player int) cfr.InfoSet {
+	return &InfoSet{
+		Card:    k.cards[player],
+		Public:  k.public,
+		History: k.history,
+	}
+}
+
+// InfoSetKey implements cfr.GameTreeNode.
+func (k *LeducNode) InfoSetKey(player int) []byte {
+	return k.InfoSet(player).Key()
+}
+
+// LegalActions implements cfr.GameTreeNode. Only legal actions have
+// a child node, so all children are legal.
+func (k *LeducNode) LegalActions() []bool {
+	return nil
+}
+
+// isLegal returns whether the current player may take the given action.
+// Folding is only allowed when facing a raise, and raising only while
+// fewer than MAX_RAISES have been made in the current round.
+func (k *LeducNode) isLegal(action byte) bool {
+	switch action {
+	case ACTION_FOLD:
+		return k.pot[k.player] < k.pot[1-k.player]
+	case ACTION_RAISE:
+		return k.raises < MAX_RAISES
+	}
+
+	return true
+}
+
+func (k *LeducNode) buildChildren() {
+	switch {
+	case k.IsTerminal():
+		return
+	case k.player == NODE_CHANCE:
+		k.buildChanceChildren()
+	default:
+		k.children = make([]LeducNode, 0, N_ACTIONS)
+		for _, action := range ACTIONS {
+			if k.isLegal(action) {
+				k.children = append(k.children, k.act(action))
+			}
+		}
+	}
+}
+
+// buildChanceChildren deals the next card: each player's private card,
+// and then the public card after the first round.
+func (k *LeducNode) buildChanceChildren() {
+	// Number of remaining cards of each rank.
+	var remaining [len(CARDS)]int
+	nRemaining := 0
+	for i, c := range CARDS {
+		remaining[i] = N_PER_RANK
+		for _, dealt := range [...]Card{k.cards[0], k.cards[1], k.public} {
+			if dealt == c {
+				remaining[i]--
+			}
+		}
+		nRemaining += remaining[i]
+	}
+
+	for i, c := range CARDS {
+		if remaining[i] == 0 {
+			continue
+		}
+
+		child := *k
+		child.parent = k
+		child.children = nil
+		child.probabilities = nil
+		child.path = k.path + c.String()
+		switch {
+		case k.cards[0] == NO_CARD:
+			child.cards[0] = c
+		case k.cards[1] == NO_CARD:
+			child.cards[1] = c
+			child.player = NODE_P0
+		default:
+			child.public = c
+			child.player = NODE_P0
+		}
+
+		k.children = append(k.children, child)
+		k.probabilities = append(k.probabilities, float64(remaining[i])/float64(nRemaining))
+	}
+}
+
+// act returns the child node after the current player takes the given action.
+func (k *LeducNode) act(action byte) LeducNode {
+	child := *k
+	child.parent = k
+	child.children = nil
+	child.probabilities = nil
+	child.path = k.path + string([]byte{action})
+	child.history = k.history + string([]byte{action})
+	child.player = 1 - k.player
+
+	opponent := 1 - k.player
+	switch action {
+	case ACTION_FOLD:
+		child.folder = k.player
+	case ACTION_CALL:
+		child.pot[k.player] = k.pot[opponent]
+		// The round is over once both players have acted, and the last one called.
+		roundHistory := child.history[len(child.history)-child.actionsThisRound():]
+		if len(roundHistory) >= 2 {
+			if k.round == N_ROUNDS-1 {
+				child.showing = true
+			} else {
+				child.round++
+				child.raises = 0
+				child.history += string([]byte{ROUND_SEP})
+				child.player = NODE_CHANCE
+			}
+		}
+	case ACTION_RAISE:
+		child.pot[k.player] = k.pot[opponent] + RAISE_SIZE[k.round]
+		child.raises++
+	}
+
+	return child
+}
+
+// actionsThisRound returns the number of actions taken in the current round.
+func (k *LeducNode) actionsThisRound() int {
+	n := 0
+	for i := len(k.history) - 1; i >= 0 && k.history[i] != ROUND_SEP; i-- {
+		n++
+	}
+
+	return n
+}
+
+// InfoSet is the information available to a player: their own card,
+// the public card once dealt, and the betting history.
+type InfoSet struct {
+	Card    Card
+	Public  Card
+	History string
+}
+
+// Key implements cfr.InfoSet.
+func (is *InfoSet) Key() []byte {
+	key := make([]byte, 0, 2+len(is.History))
+	key = append(key, byte(is.Card), is.Public.String()[0])
+	return append(key, is.History...)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (is *InfoSet) MarshalBinary() ([]byte, error) {
+	return is.Key(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (is *InfoSet) UnmarshalBinary(buf []byte) error {
+	if len(buf) < 2 {
+		return fmt.Errorf("leduc: InfoSet too short: %q", buf)
+	}
+
+	is.Card = Card(buf[0])
+	is.Public = NO_CARD
+	if buf[1] != '?' {
+		is.Public = Card(buf[1])
+	}
+	is.History = string(buf[2:])
+	return nil
+}
+
+func init() {
+	gob.Register(&InfoSet{})
+}
@@ -0,0 +1,145 @@
+package leduc
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+	"github.com/tam0705/go-cfr/internal/cfrtest"
+	"github.com/tam0705/go-cfr/sampling"
+	"github.com/tam0705/go-cfr/tree"
+)
+
+func TestGameTree(t *testing.T) {
+	root := NewGame()
+	if n := tree.CountNodes(root); n != 1939 {
+		t.Errorf("expected 1939 nodes, got %d", n)
+	}
+
+	if n := tree.CountTerminalNodes(root); n != 1116 {
+		t.Errorf("expected 1116 terminal nodes, got %d", n)
+	}
+
+	// 6 first round histories x 3 cards, plus 5 first round histories that
+	// continue x 6 second round histories x 3 cards x 3 public cards.
+	if n := tree.CountInfoSets(root); n != 6*3+5*6*3*3 {
+		t.Errorf("expected 288 infosets, got %d", n)
+	}
+
+	if problems := tree.Validate(root); len(problems) > 0 {
+		t.Errorf("invalid game tree: %v", problems)
+	}
+}
+
+func TestUtility(t *testing.T) {
+	for _, tc := range []struct {
+		history string
+		want    float64
+	}{
+		{"KQrcKcc", 3},     // Player 0 pairs the public card.
+		{"KQrcJcrc", 7},    // Player 0 has the higher card.
+		{"JQrrf", -3},      // Player 0 folds to a re-raise.
+		{"QQccJcc", 0},     // Tie.
+		{"JKcrcQrrc", -11}, // Player 1 has the higher card after two raises.
+		{"JKcrcJcrrf", 7},  // Player 1 folds to a re-raise.
+		{"KJcrrcQcrf", -5}, // Player 0 folds to a raise.
+	} {
+		node := NewGame().GetNode(tc.history)
+		if node == nil {
+			t.Errorf("%s: node not found", tc.history)
+			continue
+		}
+
+		if node.Type() != cfr.TerminalNodeType {
+			t.Errorf("%s: expected a terminal node, got %v", tc.history, node.Type())
+			continue
+		}
+
+		if u := node.Utility(NODE_P0); u != tc.want {
+			t.Errorf("%s: expected utility %v for player 0, got %v", tc.history, tc.want, u)
+		}
+
+		if u := node.Utility(NODE_P1); u != -tc.want {
+			t.Errorf("%s: expected utility %v for player 1, got %v", tc.history, -tc.want, u)
+		}
+	}
+}
+
+func TestLegalActions(t *testing.T) {
+	for _, tc := range []struct {
+		history string
+		want    string
+	}{
+		{"KQ", "cr"},
+		{"KQr", "fcr"},
+		{"KQrr", "fc"},
+		{"KQcrr", "fc"},
+		{"KQrcJ", "cr"},
+	} {
+		node := NewGame().GetNode(tc.history).(*LeducNode)
+		var actions []byte
+		for i := 0; i < node.NumChildren(); i++ {
+			child := node.GetChild(i).(*LeducNode)
+			actions = append(actions, child.path[len(child.path)-1])
+		}
+
+		if string(actions) != tc.want {
+			t.Errorf("%s: expected actions %s, got %s", tc.history, tc.want, actions)
+		}
+	}
+}
+
+func TestInfoSet(t *testing.T) {
+	node := NewGame().GetNode("KQrcJc")
+	if key := string(node.InfoSetKey(NODE_P1)); key != "QJrc/c" {
+		t.Errorf("expected infoset key QJrc/c, got %s", key)
+	}
+
+	if key := string(NewGame().GetNode("KQr").InfoSetKey(NODE_P1)); key != "Q?r" {
+		t.Errorf("expected infoset key Q?r, got %s", key)
+	}
+
+	is := node.InfoSet(NODE_P1)
+	buf, err := is.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded InfoSet
+	if err := decoded.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded != *is.(*InfoSet) {
+		t.Errorf("expected %v, got %v", is, decoded)
+	}
+}
+
+func TestCompiledCFR(t *testing.T) {
+	compiled, err := tree.Compile(context.Background(), NewGame(), N_PLAYERS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := tree.NewCompiledCFR(compiled, cfr.DiscountParams{LinearWeighting: true})
+	for i := 0; i < 2000; i++ {
+		c.Run()
+	}
+
+	ev := tree.ExpectedValue(NewGame(), c.GetNodeAverageStrategy)
+
+	if math.Abs(ev-GAME_VALUE) > 0.01 {
+		t.Errorf("expected game value %.4f, got %.4f", GAME_VALUE, ev)
+	}
+}
+
+func TestMCCFR(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	profile := cfr.NewPolicyTable(cfr.DiscountParams{LinearWeighting: true})
+	cfrtest.Train(profile, NewGame(), sampling.NewExternalSampler(), 50000)
+	cfrtest.CheckGameValue(t, "MCCFR", NewGame(), profile, GAME_VALUE, 0.02)
+}
@@ -0,0 +1,379 @@
+// Package liarsdice implements two-player Liar's Dice.
+//
+// Each player rolls their own dice in secret. Starting with player 0, the
+// players take turns either making a bid that is higher than the last one,
+// or calling the last bid a lie. A bid (q, f) claims that at least q of all
+// the dice show face f, and bids are ordered first by quantity, then by face.
+// When a bid is called, the bidder wins if it was true and loses otherwise.
+package liarsdice
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math/rand"
+
+	"github.com/tam0705/go-cfr"
+)
+
+const (
+	NODE_CHANCE = -1
+	NODE_P0     = 0
+	NODE_P1     = 1
+)
+
+// Params configures a game of Liar's Dice.
+type Params struct {
+	DicePerPlayer int
+	Faces         int
+}
+
+// NumBids returns the number of distinct bids.
+func (p Params) NumBids() int {
+	return 2 * p.DicePerPlayer * p.Faces
+}
+
+// Bid returns the quantity and face of the bid with the given index.
+func (p Params) Bid(i int) (quantity, face int) {
+	return i/p.Faces + 1, i%p.Faces + 1
+}
+
+// roll is a possible outcome of rolling one player's dice, in sorted order.
+type roll struct {
+	dice []byte
+	prob float64
+}
+
+// game holds the configuration shared by all nodes of a game tree.
+type game struct {
+	params Params
+	rolls  []roll
+	// probs are the probabilities of each roll.
+	probs []float64
+	// liar is the index of the action that calls the last bid.
+	liar int
+}
+
+// LiarsDiceNode implements cfr.GameTreeNode for Liar's Dice.
+type LiarsDiceNode struct {
+	parent        *LiarsDiceNode
+	game          *game
+	player        int
+	children      []LiarsDiceNode
+	probabilities []float64
+
+	dice [2][]byte
+	// history has one byte per action: the index of each bid, or game.liar.
+	history string
+}
+
+// NewGame returns the root of a new Liar's Dice game tree.
+func NewGame(params Params) *LiarsDiceNode {
+	if params.DicePerPlayer < 1 || params.Faces < 2 {
+		panic(fmt.Errorf("liarsdice: invalid params %+v", params))
+	}
+	if params.NumBids() >= 255 {
+		panic(fmt.Errorf("liarsdice: too many bids: %d", params.NumBids()))
+	}
+
+	g := &game{
+		params: params,
+		liar:   params.NumBids(),
+	}
+
+	for _, dice := range sortedRolls(params.DicePerPlayer, params.Faces) {
+		r := roll{dice: dice, prob: rollProbability(dice, params.Faces)}
+		g.rolls = append(g.rolls, r)
+		g.probs = append(g.probs, r.prob)
+	}
+
+	return &LiarsDiceNode{game: g, player: NODE_CHANCE}
+}
+
+// sortedRolls returns all outcomes of rolling n dice, as sorted faces.
+func sortedRolls(n, faces int) [][]byte {
+	var result [][]byte
+	var visit func(prefix []byte, minFace int)
+	visit = func(prefix []byte, minFace int) {
+		if len(prefix) == n {
+			result = append(result, append([]byte(nil), prefix...))
+			return
+		}
+
+		for f := minFace; f <= faces; f++ {
+			visit(append(prefix, byte(f)), f)
+		}
+	}
+
+	visit(make([]byte, 0, n), 1)
+	return result
+}
+
+// rollProbability returns the probability of rolling the given sorted dice,
+// which is the multinomial coefficient of the face counts over faces^n.
+func rollProbability(dice []byte, faces int) float64 {
+	p := 1.0
+	run := 0
+	for i := range dice {
+		if i > 0 && dice[i] == dice[i-1] {
+			run++
+		} else {
+			run = 1
+		}
+
+		// Multiply by (i+1) / run, one die at a time.
+		p *= float64(i+1) / float64(run) / float64(faces)
+	}
+
+	return p
+}
+
+// String implements fmt.Stringer.
+func (k LiarsDiceNode) String() string {
+	bids := make([]string, len(k.history))
+	for i := range k.history {
+		bids[i] = k.actionString(int(k.history[i]))
+	}
+
+	return fmt.Sprintf("Player %v's turn. Dice: %v %v Bids: %v",
+		k.player, k.dice[0], k.dice[1], bids)
+}
+
+func (k *LiarsDiceNode) actionString(action int) string {
+	if action == k.game.liar {
+		return "liar"
+	}
+
+	q, f := k.game.params.Bid(action)
+	return fmt.Sprintf("%dx%d", q, f)
+}
+
+// GetNode implements cfr.GameTreeNode. Liar's Dice nodes are not addressable
+// by a history string, so it always returns nil.
+func (k *LiarsDiceNode) GetNode(history string) cfr.GameTreeNode {
+	return nil
+}
+
+// Close implements cfr.GameTreeNode.
+func (k *LiarsDiceNode) Close() {
+	k.children = nil
+	k.probabilities = nil
+}
+
+// Type implements cfr.GameTreeNode.
+func (k *LiarsDiceNode) Type() cfr.NodeType {
+	if k.IsTerminal() {
+		return cfr.TerminalNodeType
+	} else if k.player == NODE_CHANCE {
+		return cfr.ChanceNodeType
+	}
+
+	return cfr.PlayerNodeType
+}
+
+// IsTerminal returns whether the last bid has been called.
+func (k *LiarsDiceNode) IsTerminal() bool {
+	return len(k.history) > 0 && int(k.history[len(k.history)-1]) == k.game.liar
+}
+
+// NumChildren implements cfr.GameTreeNode.
+func (k *LiarsDiceNode) NumChildren() int {
+	if k.children == nil {
+		k.buildChildren()
+	}
+
+	return len(k.children)
+}
+
+// GetChild implements cfr.GameTreeNode.
+func (k *LiarsDiceNode) GetChild(i int) cfr.GameTreeNode {
+	if k.children == nil {
+		k.buildChildren()
+	}
+
+	return &k.children[i]
+}
+
+// Parent implements cfr.GameTreeNode.
+func (k *LiarsDiceNode) Parent() cfr.GameTreeNode {
+	if k.parent == nil {
+		return nil
+	}
+
+	return k.parent
+}
+
+// GetChildProbability implements cfr.GameTreeNode.
+func (k *LiarsDiceNode) GetChildProbability(i int) float64 {
+	if k.children == nil {
+		k.buildChildren()
+	}
+	if k.probabilities == nil {
+		return 0.0
+	}
+
+	return k.probabilities[i]
+}
+
+// SampleChild implements cfr.GameTreeNode.
+func (k *LiarsDiceNode) SampleChild() (cfr.GameTreeNode, float64) {
+	nChildren := k.NumChildren()
+	if k.probabilities == nil {
+		i := rand.Intn(nChildren)
+		return k.GetChild(i), 0.0
+	}
+
+	x := rand.Float64()
+	var cumProb float64
+	for i, p := range k.probabilities {
+		cumProb += p
+		if cumProb > x {
+			return k.GetChild(i), p
+		}
+	}
+
+	return k.GetChild(nChildren - 1), k.probabilities[nChildren-1]
+}
+
+// Player implements cfr.GameTreeNode.
+func (k *LiarsDiceNode) Player() int {
+	return k.player
+}
+
+// Utility implements cfr.GameTreeNode.
+func (k *LiarsDiceNode) Utility(player int) float64 {
+	n := len(k.history)
+	q, f := k.game.params.Bid(int(k.history[n-2]))
+	count := 0
+	for _, dice := range k.dice {
+		for _, d := range dice {
+			if int(d) == f {
+				count++
+			}
+		}
+	}
+
+	// The player who called is the one who took the last action.
+	caller := (n - 1) % 2
+	winner := caller
+	if count >= q {
+		winner = 1 - caller
+	}
+
+	if player == winner {
+		return 1.0
+	}
+
+	return -1.0
+}
+
+// InfoSet implements cfr.GameTreeNode.
+func (k *LiarsDiceNode) InfoSet(player int) cfr.InfoSet {
+	return &InfoSet{
+		Dice:    k.dice[player],
+		History: k.history,
+	}
+}
+
+// InfoSetKey implements cfr.GameTreeNode.
+func (k *LiarsDiceNode) InfoSetKey(player int) []byte {
+	return k.InfoSet(player).Key()
+}
+
+// LegalActions implements cfr.GameTreeNode. Only legal actions have
+// a child node, so all children are legal.
+func (k *LiarsDiceNode) LegalActions() []bool {
+	return nil
+}
+
+func (k *LiarsDiceNode) buildChildren() {
+	if k.IsTerminal() {
+		return
+	}
+
+	if k.player == NODE_CHANCE {
+		k.buildRolls()
+		return
+	}
+
+	// Any higher bid, or calling the last bid.
+	firstBid := 0
+	if len(k.history) > 0 {
+		firstBid = int(k.history[len(k.history)-1]) + 1
+	}
+
+	for action := firstBid; action <= k.game.liar; action++ {
+		if action == k.game.liar && len(k.history) == 0 {
+			break // There is no bid to call.
+		}
+
+		k.children = append(k.children, LiarsDiceNode{
+			parent:  k,
+			game:    k.game,
+			player:  1 - k.player,
+			dice:    k.dice,
+			history: k.history + string([]byte{byte(action)}),
+		})
+	}
+}
+
+// buildRolls rolls the dice of the next player who has not rolled yet.
+func (k *LiarsDiceNode) buildRolls() {
+	p := NODE_P0
+	next := NODE_CHANCE
+	if k.dice[0] != nil {
+		p = NODE_P1
+		next = NODE_P0
+	}
+
+	k.children = make([]LiarsDiceNode, len(k.game.rolls))
+	for i, r := range k.game.rolls {
+		child := LiarsDiceNode{
+			parent: k,
+			game:   k.game,
+			player: next,
+			dice:   k.dice,
+		}
+		child.dice[p] = r.dice
+		k.children[i] = child
+	}
+
+	k.probabilities = k.game.probs
+}
+
+// InfoSet is the information available to a player: their own dice,
+// and the bids so far.
+type InfoSet struct {
+	Dice    []byte
+	History string
+}
+
+// Key implements cfr.InfoSet.
+func (is *InfoSet) Key() []byte {
+	key := make([]byte, 0, len(is.Dice)+1+len(is.History))
+	key = append(key, is.Dice...)
+	// Faces are never 0, so this separates the dice from the bids.
+	key = append(key, 0)
+	return append(key, is.History...)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (is *InfoSet) MarshalBinary() ([]byte, error) {
+	return is.Key(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (is *InfoSet) UnmarshalBinary(buf []byte) error {
+	for i, b := range buf {
+		if b == 0 {
+			is.Dice = append([]byte(nil), buf[:i]...)
+			is.History = string(buf[i+1:])
+			return nil
+		}
+	}
+
+	return fmt.Errorf("liarsdice: invalid InfoSet: %v", buf)
+}
+
+func init() {
+	gob.Register(&InfoSet{})
+}
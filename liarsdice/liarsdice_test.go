@@ -0,0 +1,178 @@
+package liarsdice
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+	"github.com/tam0705/go-cfr/internal/cfrtest"
+	"github.com/tam0705/go-cfr/sampling"
+	"github.com/tam0705/go-cfr/tree"
+)
+
+func TestGameTree(t *testing.T) {
+	root := NewGame(Params{DicePerPlayer: 1, Faces: 2})
+	// For each of the 4 rolls, 16 increasing bid sequences, and a call
+	// after each of the 15 non-empty ones.
+	if n := tree.CountNodes(root); n != 1+2+4*(16+15) {
+		t.Errorf("expected 127 nodes, got %d", n)
+	}
+
+	if n := tree.CountInfoSets(root); n != 32 {
+		t.Errorf("expected 32 infosets, got %d", n)
+	}
+
+	for _, params := range []Params{{1, 2}, {1, 4}, {2, 2}} {
+		if problems := tree.Validate(NewGame(params)); len(problems) > 0 {
+			t.Errorf("%+v: invalid game tree: %v", params, problems)
+		}
+	}
+}
+
+func TestRollProbabilities(t *testing.T) {
+	for _, params := range []Params{{1, 6}, {2, 6}, {3, 4}, {5, 6}} {
+		root := NewGame(params)
+		var total float64
+		for i := 0; i < root.NumChildren(); i++ {
+			total += root.GetChildProbability(i)
+		}
+
+		if math.Abs(total-1) > 1e-9 {
+			t.Errorf("%+v: roll probabilities sum to %v", params, total)
+		}
+	}
+
+	// Rolling two different faces is twice as likely as a pair.
+	root := NewGame(Params{DicePerPlayer: 2, Faces: 6})
+	if p := root.GetChildProbability(0); math.Abs(p-1.0/36) > 1e-9 {
+		t.Errorf("expected probability 1/36 for a pair of ones, got %v", p)
+	}
+	if p := root.GetChildProbability(1); math.Abs(p-2.0/36) > 1e-9 {
+		t.Errorf("expected probability 2/36 for a one and a two, got %v", p)
+	}
+}
+
+// play returns the node reached by rolling the given dice and then
+// taking the given actions.
+func play(params Params, dice [2][]byte, actions ...int) cfr.GameTreeNode {
+	var node cfr.GameTreeNode = NewGame(params)
+	for p := 0; p < 2; p++ {
+		for i := 0; i < node.NumChildren(); i++ {
+			child := node.GetChild(i).(*LiarsDiceNode)
+			if string(child.dice[p]) == string(dice[p]) {
+				node = child
+				break
+			}
+		}
+	}
+
+	for _, action := range actions {
+		first := 0
+		if h := node.(*LiarsDiceNode).history; len(h) > 0 {
+			first = int(h[len(h)-1]) + 1
+		}
+		node = node.GetChild(action - first)
+	}
+
+	return node
+}
+
+func TestUtility(t *testing.T) {
+	params := Params{DicePerPlayer: 2, Faces: 3}
+	dice := [2][]byte{{1, 3}, {3, 3}}
+	liar := params.NumBids()
+	for _, tc := range []struct {
+		actions []int
+		want    float64
+	}{
+		// Player 0 bids 3x3, which player 1 calls: the bid is true.
+		{[]int{8, liar}, 1},
+		// Player 0 bids 1x1, player 1 bids 4x3, and player 0 calls.
+		{[]int{0, 11, liar}, 1},
+		// Player 0 bids 2x1, which player 1 calls.
+		{[]int{3, liar}, -1},
+	} {
+		node := play(params, dice, tc.actions...)
+		if node.Type() != cfr.TerminalNodeType {
+			t.Errorf("%v: expected terminal node, got %v", node, node.Type())
+			continue
+		}
+
+		if u := node.Utility(NODE_P0); u != tc.want {
+			t.Errorf("%v: expected utility %v for player 0, got %v", node, tc.want, u)
+		}
+		if u := node.Utility(NODE_P1); u != -tc.want {
+			t.Errorf("%v: expected utility %v for player 1, got %v", node, -tc.want, u)
+		}
+	}
+}
+
+func TestInfoSet(t *testing.T) {
+	params := Params{DicePerPlayer: 2, Faces: 3}
+	node := play(params, [2][]byte{{1, 3}, {2, 2}}, 0, 4)
+	is := node.InfoSet(NODE_P0).(*InfoSet)
+	if string(is.Dice) != string([]byte{1, 3}) || is.History != string([]byte{0, 4}) {
+		t.Errorf("unexpected infoset: %+v", is)
+	}
+
+	buf, err := is.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded InfoSet
+	if err := decoded.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(decoded.Dice) != string(is.Dice) || decoded.History != is.History {
+		t.Errorf("expected %+v, got %+v", is, decoded)
+	}
+
+	// Player 1 does not observe player 0's dice.
+	other := play(params, [2][]byte{{1, 1}, {2, 2}}, 0, 4)
+	if string(node.InfoSetKey(NODE_P1)) != string(other.InfoSetKey(NODE_P1)) {
+		t.Error("player 1's infoset depends on player 0's dice")
+	}
+}
+
+func TestMCCFR(t *testing.T) {
+	params := Params{DicePerPlayer: 1, Faces: 3}
+	compiled, err := tree.Compile(context.Background(), NewGame(params), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := tree.NewCompiledCFR(compiled, cfr.DiscountParams{LinearWeighting: true})
+	for i := 0; i < 2000; i++ {
+		c.Run()
+	}
+
+	want := tree.ExpectedValue(NewGame(params), c.GetNodeAverageStrategy)
+	profile := cfr.NewPolicyTable(cfr.DiscountParams{LinearWeighting: true})
+	cfrtest.Train(profile, NewGame(params), sampling.NewExternalSampler(), 50000)
+	cfrtest.CheckGameValue(t, "MCCFR", NewGame(params), profile, want, 0.025)
+}
+
+func TestSamplers(t *testing.T) {
+	// Deep trees with many actions per node.
+	params := Params{DicePerPlayer: 2, Faces: 4}
+	profile := cfr.NewPolicyTable(cfr.DiscountParams{})
+	for _, sampler := range []cfr.Sampler{
+		sampling.NewOutcomeSampler(0.6),
+		sampling.NewMultiOutcomeSampler(3, 0.6),
+		sampling.NewRobustSampler(2),
+		sampling.NewAverageStrategySampler(sampling.AverageStrategyParams{Epsilon: 0.05, Tau: 1, Beta: 100}),
+	} {
+		opt := cfr.NewMCCFR(profile, sampler)
+		root := NewGame(params)
+		for i := 0; i < 1000; i++ {
+			ev := opt.Run(root)
+			if math.IsNaN(float64(ev)) || math.IsInf(float64(ev), 0) {
+				t.Fatalf("%T: invalid value %v at iteration %d", sampler, ev, i)
+			}
+			profile.Update()
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package cfr
+
+// IsLegal returns true if action i is allowed by the given legal-action mask.
+// A nil mask allows every action.
+func IsLegal(mask []bool, i int) bool {
+	return mask == nil || mask[i]
+}
+
+// NumLegal returns the number of the first n actions that are allowed by mask.
+func NumLegal(mask []bool, n int) int {
+	if mask == nil {
+		return n
+	}
+
+	total := 0
+	for _, legal := range mask[:n] {
+		if legal {
+			total++
+		}
+	}
+
+	return total
+}
+
+// ApplyMask zeroes the entries of v that are not allowed by mask.
+func ApplyMask(v []float32, mask []bool) {
+	if mask == nil {
+		return
+	}
+
+	for i := range v {
+		if !mask[i] {
+			v[i] = 0
+		}
+	}
+}
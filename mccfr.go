@@ -3,6 +3,7 @@ package cfr
 import (
 	"fmt"
 	"math/rand"
+	"time"
 
 	"github.com/tam0705/go-cfr/internal/f32"
 )
@@ -17,21 +18,95 @@ type Sampler interface {
 	Sample(GameTreeNode, NodePolicy) []float32
 }
 
+// SampleContext describes where in the current MCCFR traversal
+// a node being sampled is.
+type SampleContext struct {
+	// Iter is the current iteration of the StrategyProfile.
+	Iter int
+	// Depth is the number of edges between the root of the traversal and the node.
+	Depth int
+	// TraversingPlayer is the player whose regrets are being updated.
+	TraversingPlayer int
+	// SampleProb is the probability with which the traversing player's
+	// actions leading to the node were sampled, including any chance weights.
+	SampleProb float32
+	// PlayerReach is the probability that the traversing player plays
+	// to the node under their current strategy.
+	PlayerReach float32
+	// OpponentReach is the probability that the opponents and chance
+	// play to the node, along the sampled trajectory.
+	OpponentReach float32
+}
+
+// ContextSampler is a Sampler that is also told where in the traversal
+// the node being sampled is, which allows adaptive sampling schemes.
+type ContextSampler interface {
+	// SampleWithContext is like Sample, with the addition of the node's
+	// SampleContext.
+	SampleWithContext(GameTreeNode, NodePolicy, SampleContext) []float32
+}
+
+// IgnoreContext adapts a Sampler to a ContextSampler that ignores
+// the SampleContext.
+func IgnoreContext(sampler Sampler) ContextSampler {
+	if cs, ok := sampler.(ContextSampler); ok {
+		return cs
+	}
+
+	return contextFreeSampler{sampler}
+}
+
+type contextFreeSampler struct {
+	Sampler
+}
+
+func (s contextFreeSampler) SampleWithContext(node GameTreeNode, policy NodePolicy, sc SampleContext) []float32 {
+	return s.Sample(node, policy)
+}
+
+// ChanceSampler selects a subset of the children of a chance node to traverse.
+type ChanceSampler interface {
+	// SampleChance returns a vector of weights for the N children of
+	// the given chance node. Children with w > 0 will be traversed, and
+	// their counterfactual values scaled by w. The expected weight of
+	// each child must equal its probability (GetChildProbability) for
+	// the resulting estimates to be unbiased. The returned slice may be
+	// reused between calls to SampleChance.
+	SampleChance(node GameTreeNode) []float32
+}
+
 type MCCFR struct {
 	strategyProfile StrategyProfile
-	sampler         Sampler
+	sampler         ContextSampler
+	chanceSampler   ChanceSampler
 
 	slicePool *floatSlicePool
 	mapPool   *keyIntMapPool
 	rng       *rand.Rand
 
+	diagnostics *Diagnostics
+
 	traversingPlayer int
 	sampledActions   map[string]int
+
+	// Position of the current node in the traversal.
+	depth         int
+	playerReach   float32
+	opponentReach float32
+	// Product of the ChanceSampler weights along the path to the node,
+	// which are included in sampleProb.
+	chanceWeight float32
 }
 
 const eps = 1e-3
 
 func NewMCCFR(strategyProfile StrategyProfile, sampler Sampler) *MCCFR {
+	return NewContextMCCFR(strategyProfile, IgnoreContext(sampler))
+}
+
+// NewContextMCCFR creates a new MCCFR that samples player nodes with the
+// given ContextSampler.
+func NewContextMCCFR(strategyProfile StrategyProfile, sampler ContextSampler) *MCCFR {
 	return &MCCFR{
 		strategyProfile: strategyProfile,
 		sampler:         sampler,
@@ -41,15 +116,63 @@ func NewMCCFR(strategyProfile StrategyProfile, sampler Sampler) *MCCFR {
 	}
 }
 
+// SetChanceSampler sets the ChanceSampler used to select the children of
+// chance nodes. If it is nil (the default), a single child is sampled
+// with SampleChild.
+func (c *MCCFR) SetChanceSampler(chanceSampler ChanceSampler) {
+	c.chanceSampler = chanceSampler
+}
+
+// SetDiagnostics sets the Diagnostics in which statistics of each
+// subsequent Run are recorded. If it is nil (the default), no
+// statistics are recorded.
+func (c *MCCFR) SetDiagnostics(diagnostics *Diagnostics) {
+	c.diagnostics = diagnostics
+}
+
 func (c *MCCFR) Run(node GameTreeNode) float32 {
 	iter := c.strategyProfile.Iter()
-	c.traversingPlayer = int((iter+1) % 2)
+	c.traversingPlayer = int((iter + 1) % 2)
 	c.sampledActions = c.mapPool.alloc()
 	defer c.mapPool.free(c.sampledActions)
-	return c.runHelper(node, node.Player(), 1.0)
+	c.depth = 0
+	c.playerReach = 1.0
+	c.opponentReach = 1.0
+	c.chanceWeight = 1.0
+	if c.diagnostics == nil {
+		return c.runHelper(node, node.Player(), 1.0)
+	}
+
+	start := time.Now()
+	ev := c.runHelper(node, node.Player(), 1.0)
+	c.diagnostics.endIteration(c.traversingPlayer, time.Since(start))
+	return ev
+}
+
+// visitChild traverses the given child node, one level deeper in the tree.
+func (c *MCCFR) visitChild(child GameTreeNode, lastPlayer int, sampleProb float32) float32 {
+	c.depth++
+	ev := c.runHelper(child, lastPlayer, sampleProb)
+	c.depth--
+	return ev
+}
+
+func (c *MCCFR) sampleContext(sampleProb float32) SampleContext {
+	return SampleContext{
+		Iter:             c.strategyProfile.Iter(),
+		Depth:            c.depth,
+		TraversingPlayer: c.traversingPlayer,
+		SampleProb:       sampleProb,
+		PlayerReach:      c.playerReach,
+		OpponentReach:    c.opponentReach,
+	}
 }
 
 func (c *MCCFR) runHelper(node GameTreeNode, lastPlayer int, sampleProb float32) float32 {
+	if c.diagnostics != nil {
+		c.diagnostics.nodes++
+	}
+
 	var ev float32
 	switch node.Type() {
 	case TerminalNodeType:
@@ -66,9 +189,36 @@ func (c *MCCFR) runHelper(node GameTreeNode, lastPlayer int, sampleProb float32)
 }
 
 func (c *MCCFR) handleChanceNode(node GameTreeNode, lastPlayer int, sampleProb float32) float32 {
-	child, _ := node.SampleChild()
-	// Sampling probabilities cancel out in the calculation of counterfactual value.
-	return c.runHelper(child, lastPlayer, sampleProb)
+	reach := c.opponentReach
+	if c.chanceSampler == nil {
+		child, p := node.SampleChild()
+		// Sampling probabilities cancel out in the calculation of counterfactual value.
+		c.opponentReach = reach * float32(p)
+		ev := c.visitChild(child, lastPlayer, sampleProb)
+		c.opponentReach = reach
+		return ev
+	}
+
+	ws := c.slicePool.alloc(node.NumChildren())
+	copy(ws, c.chanceSampler.SampleChance(node))
+
+	var ev float32
+	chanceWeight := c.chanceWeight
+	for i, w := range ws {
+		if w > 0 {
+			// Dividing the sample probability by w scales the child's
+			// counterfactual value, and any regrets accumulated below it, by w.
+			child := node.GetChild(i)
+			c.opponentReach = reach * float32(node.GetChildProbability(i))
+			c.chanceWeight = chanceWeight * w
+			ev += c.visitChild(child, lastPlayer, sampleProb/w)
+		}
+	}
+
+	c.opponentReach = reach
+	c.chanceWeight = chanceWeight
+	c.slicePool.free(ws)
+	return ev
 }
 
 func (c *MCCFR) handlePlayerNode(node GameTreeNode, sampleProb float32) float32 {
@@ -82,32 +232,46 @@ func (c *MCCFR) handlePlayerNode(node GameTreeNode, sampleProb float32) float32
 func (c *MCCFR) handleTraversingPlayerNode(node GameTreeNode, sampleProb float32) float32 {
 	player := node.Player()
 	nChildren := node.NumChildren()
-	if nChildren == 1 {
+	mask := node.LegalActions()
+	if nChildren == 1 || NumLegal(mask, nChildren) == 1 {
 		// Optimization to skip trivial nodes with no real choice.
-		child := node.GetChild(0)
-		return c.runHelper(child, player, sampleProb)
+		child := node.GetChild(firstLegal(mask))
+		return c.visitChild(child, player, sampleProb)
 	}
 
 	policy := c.strategyProfile.GetPolicy(node)
 	qs := c.slicePool.alloc(nChildren)
-	copy(qs, c.sampler.Sample(node, policy))
+	copy(qs, c.sampler.SampleWithContext(node, policy, c.sampleContext(sampleProb)))
+	ApplyMask(qs, mask)
 	regrets := c.slicePool.alloc(nChildren)
 	oldSampledActions := c.sampledActions
 	c.sampledActions = c.mapPool.alloc()
-	
+
+	strat := policy.GetLegalStrategy(mask)
+	reach := c.playerReach
 	for i, q := range qs {
 		child := node.GetChild(i)
 		var util float32
 		if q > 0 {
-			util = c.runHelper(child, player, q*sampleProb)
+			c.playerReach = reach * strat[i]
+			util = c.visitChild(child, player, q*sampleProb)
 		}
 
 		regrets[i] = util
 	}
-	
-	cfValue := f32.DotUnitary(policy.GetStrategy(), regrets)
+	c.playerReach = reach
+
+	cfValue := f32.DotUnitary(strat, regrets)
 	f32.AddConst(-cfValue, regrets)
-	policy.AddRegret(1.0/sampleProb, qs, regrets)
+	// Illegal actions accumulate no regret.
+	ApplyMask(regrets, mask)
+	// The counterfactual values are already scaled by the chance weights,
+	// so they are not applied to the regrets again.
+	w := 1.0 / (sampleProb * c.chanceWeight)
+	policy.AddRegret(w, qs, regrets)
+	if c.diagnostics != nil {
+		c.diagnostics.observe(node.InfoSetKey(player), player, w, cfValue, regrets)
+	}
 	policy.NextStrategy(1.0, 1.0, 1.0)
 
 	c.slicePool.free(qs)
@@ -121,26 +285,38 @@ func (c *MCCFR) handleTraversingPlayerNode(node GameTreeNode, sampleProb float32
 // Save selected action so that they are reused if this infoset is hit again.
 func (c *MCCFR) handleSampledPlayerNode(node GameTreeNode, sampleProb float32) float32 {
 	policy := c.strategyProfile.GetPolicy(node)
+	mask := node.LegalActions()
 
 	// Update average strategy for this node.
 	// We perform "stochastic" updates as described in the MC-CFR paper.
 	if sampleProb > 0 {
-		policy.AddStrategyWeight(1.0 / sampleProb)
+		policy.AddLegalStrategyWeight(1.0/sampleProb, mask)
 	}
 
 	// Sampling probabilities cancel out in the calculation of counterfactual value,
 	// so we don't include them here.
-	child := node.GetChild(getOrSample(c.sampledActions, node, policy, c.rng))
-	return c.runHelper(child, node.Player(), sampleProb)
+	selected := getOrSample(c.sampledActions, node, policy, c.rng)
+	reach := c.opponentReach
+	c.opponentReach = reach * policy.GetLegalStrategy(mask)[selected]
+	child := node.GetChild(selected)
+	ev := c.visitChild(child, node.Player(), sampleProb)
+	c.opponentReach = reach
+	return ev
 }
 
 func getOrSample(sampledActions map[string]int, node GameTreeNode, policy NodePolicy, rng *rand.Rand) int {
 	key := node.InfoSetKey(node.Player())
+	mask := node.LegalActions()
 	selected, ok := sampledActions[string(key)]
 	if !ok {
 		x := rng.Float32()
-		selected = sampleOne(policy.GetStrategy(), x)
+		selected = sampleOne(policy.GetLegalStrategy(mask), x)
 		sampledActions[string(key)] = selected
+	} else if !IsLegal(mask, selected) {
+		// The infoset was reached before with a different set of legal
+		// actions, and the action chosen there is not available here.
+		x := rng.Float32()
+		selected = sampleOne(policy.GetLegalStrategy(mask), x)
 	}
 
 	if selected >= node.NumChildren() {
@@ -167,10 +343,20 @@ func sampleOne(pv []float32, x float32) int {
 	return len(pv) - 1
 }
 
+func firstLegal(mask []bool) int {
+	for i, legal := range mask {
+		if legal {
+			return i
+		}
+	}
+
+	return 0
+}
+
 func getSign(player1, player2 int) float32 {
 	if player1 == player2 {
 		return 1.0
 	}
 
 	return -1.0
-}
\ No newline at end of file
+}
@@ -0,0 +1,154 @@
+// Package nlhe implements a card-level engine for no-limit Texas hold'em,
+// on top of the card and action types of package Def.
+//
+// A Hand plays a single hand for 2 to 9 seats: it posts the antes and
+// blinds, deals hole and board cards from a Deck, enforces the betting
+// rules (including minimum raises and all-ins that do not reopen the
+// betting), and at the end returns uncalled bets and distributes the main
+// and side pots at showdown.
+package nlhe
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	Def "github.com/tam0705/go-cfr/def"
+)
+
+const (
+	CLUBS    byte = 1 // Def's plums.
+	DIAMONDS byte = 2
+	HEARTS   byte = 3
+	SPADES   byte = 4
+)
+
+const (
+	TWO byte = 2
+	ACE byte = 14
+)
+
+const rankChars = "23456789TJQKA"
+const suitChars = "cdhs"
+
+// CardString returns the two-character name of a card, e.g. "As" or "Td".
+func CardString(card Def.Poker) string {
+	if card.Num < TWO || card.Num > ACE || card.Kind < CLUBS || card.Kind > SPADES {
+		return "??"
+	}
+
+	return string([]byte{rankChars[card.Num-TWO], suitChars[card.Kind-CLUBS]})
+}
+
+// CardsString returns the space-separated names of the given cards.
+func CardsString(cards []Def.Poker) string {
+	names := make([]string, len(cards))
+	for i, card := range cards {
+		names[i] = CardString(card)
+	}
+
+	return strings.Join(names, " ")
+}
+
+// ParseCard parses a card name such as "As" or "Td".
+func ParseCard(s string) (Def.Poker, error) {
+	if len(s) != 2 {
+		return Def.Poker{}, fmt.Errorf("nlhe: invalid card: %q", s)
+	}
+
+	rank := strings.IndexByte(rankChars, strings.ToUpper(s[:1])[0])
+	suit := strings.IndexByte(suitChars, strings.ToLower(s[1:])[0])
+	if rank < 0 || suit < 0 {
+		return Def.Poker{}, fmt.Errorf("nlhe: invalid card: %q", s)
+	}
+
+	return Def.Poker{Num: TWO + byte(rank), Kind: CLUBS + byte(suit)}, nil
+}
+
+// ParseCards parses space-separated card names, such as "As Kd 7c".
+func ParseCards(s string) ([]Def.Poker, error) {
+	var cards []Def.Poker
+	for _, name := range strings.Fields(s) {
+		card, err := ParseCard(name)
+		if err != nil {
+			return nil, err
+		}
+
+		cards = append(cards, card)
+	}
+
+	return cards, nil
+}
+
+// MustParseCards is like ParseCards, but panics if s is invalid.
+func MustParseCards(s string) []Def.Poker {
+	cards, err := ParseCards(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return cards
+}
+
+// Deck is a deck of 52 cards, which are dealt in order.
+type Deck struct {
+	cards []Def.Poker
+	next  int
+}
+
+// NewDeck returns a new deck in a fixed order.
+func NewDeck() *Deck {
+	cards := make([]Def.Poker, 0, 52)
+	for suit := CLUBS; suit <= SPADES; suit++ {
+		for rank := TWO; rank <= ACE; rank++ {
+			cards = append(cards, Def.Poker{Num: rank, Kind: suit})
+		}
+	}
+
+	return &Deck{cards: cards}
+}
+
+// NewShuffledDeck returns a new deck shuffled with the given source
+// of randomness.
+func NewShuffledDeck(rng *rand.Rand) *Deck {
+	d := NewDeck()
+	d.Shuffle(rng)
+	return d
+}
+
+// Shuffle shuffles the cards that have not been dealt yet.
+func (d *Deck) Shuffle(rng *rand.Rand) {
+	remaining := d.cards[d.next:]
+	rng.Shuffle(len(remaining), func(i, j int) {
+		remaining[i], remaining[j] = remaining[j], remaining[i]
+	})
+}
+
+// Remove removes the given cards from the cards that have not been
+// dealt yet, so that they cannot be dealt.
+func (d *Deck) Remove(cards ...Def.Poker) {
+	for _, card := range cards {
+		for i := d.next; i < len(d.cards); i++ {
+			if d.cards[i] == card {
+				d.cards = append(d.cards[:i], d.cards[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Remaining returns the number of cards that have not been dealt.
+func (d *Deck) Remaining() int {
+	return len(d.cards) - d.next
+}
+
+// Deal deals the next card. It panics if the deck is empty.
+func (d *Deck) Deal() Def.Poker {
+	if d.next == len(d.cards) {
+		panic("nlhe: deck is empty")
+	}
+
+	card := d.cards[d.next]
+	d.next++
+	return card
+}
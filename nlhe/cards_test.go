@@ -0,0 +1,55 @@
+package nlhe
+
+import (
+	"math/rand"
+	"testing"
+
+	Def "github.com/tam0705/go-cfr/def"
+)
+
+func TestParseCards(t *testing.T) {
+	cards, err := ParseCards("As Td 2c 9h")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Def.Poker{{Num: ACE, Kind: SPADES}, {Num: 10, Kind: DIAMONDS}, {Num: TWO, Kind: CLUBS}, {Num: 9, Kind: HEARTS}}
+	for i := range want {
+		if cards[i] != want[i] {
+			t.Errorf("card %d: expected %+v, got %+v", i, want[i], cards[i])
+		}
+	}
+
+	if s := CardsString(cards); s != "As Td 2c 9h" {
+		t.Errorf("expected %q, got %q", "As Td 2c 9h", s)
+	}
+
+	for _, s := range []string{"A", "1s", "Ax", "10s"} {
+		if _, err := ParseCard(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestDeck(t *testing.T) {
+	deck := NewShuffledDeck(rand.New(rand.NewSource(1)))
+	deck.Remove(MustParseCards("As Kd")...)
+	if n := deck.Remaining(); n != 50 {
+		t.Errorf("expected 50 cards, got %d", n)
+	}
+
+	seen := make(map[Def.Poker]bool)
+	for deck.Remaining() > 0 {
+		card := deck.Deal()
+		if seen[card] || CardString(card) == "??" {
+			t.Errorf("dealt invalid or duplicate card %+v", card)
+		}
+		seen[card] = true
+	}
+
+	for _, card := range MustParseCards("As Kd") {
+		if seen[card] {
+			t.Errorf("dealt removed card %s", CardString(card))
+		}
+	}
+}
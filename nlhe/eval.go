@@ -0,0 +1,163 @@
+package nlhe
+
+import (
+	"fmt"
+	"sort"
+
+	Def "github.com/tam0705/go-cfr/def"
+)
+
+// HandCategory is the category of a five-card poker hand.
+type HandCategory uint8
+
+const (
+	HIGH_CARD HandCategory = iota
+	ONE_PAIR
+	TWO_PAIR
+	THREE_OF_A_KIND
+	STRAIGHT
+	FLUSH
+	FULL_HOUSE
+	FOUR_OF_A_KIND
+	STRAIGHT_FLUSH
+)
+
+var categoryNames = [...]string{
+	"high card", "one pair", "two pair", "three of a kind", "straight",
+	"flush", "full house", "four of a kind", "straight flush",
+}
+
+// String implements fmt.Stringer.
+func (c HandCategory) String() string {
+	if int(c) < len(categoryNames) {
+		return categoryNames[c]
+	}
+
+	return fmt.Sprintf("HandCategory(%d)", c)
+}
+
+// HandRank is the strength of the best five-card hand among some cards.
+// A stronger hand has a greater HandRank, and hands of equal strength
+// have equal HandRanks.
+type HandRank uint32
+
+// Category returns the category of the hand.
+func (r HandRank) Category() HandCategory {
+	return HandCategory(r >> 20)
+}
+
+// String implements fmt.Stringer.
+func (r HandRank) String() string {
+	return r.Category().String()
+}
+
+// newHandRank returns the rank of a hand of the given category, followed
+// by the ranks of its cards in order of significance.
+func newHandRank(category HandCategory, ranks ...byte) HandRank {
+	r := HandRank(category)
+	for i := 0; i < 5; i++ {
+		r <<= 4
+		if i < len(ranks) {
+			r |= HandRank(ranks[i])
+		}
+	}
+
+	return r
+}
+
+// Evaluate returns the rank of the best five-card hand among the given
+// five to seven cards.
+func Evaluate(cards ...Def.Poker) HandRank {
+	n := len(cards)
+	if n < 5 || n > 7 {
+		panic(fmt.Errorf("nlhe: cannot evaluate %d cards", n))
+	}
+
+	var best HandRank
+	var hand [5]Def.Poker
+	var choose func(next, k int)
+	choose = func(next, k int) {
+		if k == len(hand) {
+			if r := evaluate5(hand); r > best {
+				best = r
+			}
+			return
+		}
+
+		for i := next; i <= n-len(hand)+k; i++ {
+			hand[k] = cards[i]
+			choose(i+1, k+1)
+		}
+	}
+	choose(0, 0)
+
+	return best
+}
+
+// EvaluateCards returns the rank of the best five-card hand among the
+// hole and board cards that have been dealt. Cards that have not been
+// dealt are zero.
+func EvaluateCards(cards Def.Cards) HandRank {
+	dealt := make([]Def.Poker, 0, len(cards))
+	for _, card := range cards {
+		if card.Num != 0 {
+			dealt = append(dealt, card)
+		}
+	}
+
+	return Evaluate(dealt...)
+}
+
+// evaluate5 returns the rank of a five-card hand.
+func evaluate5(hand [5]Def.Poker) HandRank {
+	var counts [ACE + 1]byte
+	flush := true
+	for _, card := range hand {
+		counts[card.Num]++
+		if card.Kind != hand[0].Kind {
+			flush = false
+		}
+	}
+
+	// The distinct ranks, by decreasing count and then rank.
+	var ranks []byte
+	for rank := ACE; rank >= TWO; rank-- {
+		if counts[rank] > 0 {
+			ranks = append(ranks, rank)
+		}
+	}
+	sort.SliceStable(ranks, func(i, j int) bool {
+		return counts[ranks[i]] > counts[ranks[j]]
+	})
+
+	straight := byte(0)
+	if len(ranks) == 5 {
+		if ranks[0]-ranks[4] == 4 {
+			straight = ranks[0]
+		} else if ranks[0] == ACE && ranks[1] == 5 {
+			// The wheel, A-2-3-4-5, is a five-high straight.
+			straight = 5
+		}
+	}
+
+	switch {
+	case straight > 0 && flush:
+		return newHandRank(STRAIGHT_FLUSH, straight)
+	case counts[ranks[0]] == 4:
+		return newHandRank(FOUR_OF_A_KIND, ranks...)
+	case counts[ranks[0]] == 3 && counts[ranks[1]] == 2:
+		return newHandRank(FULL_HOUSE, ranks...)
+	case flush:
+		return newHandRank(FLUSH, ranks...)
+	case straight > 0:
+		return newHandRank(STRAIGHT, straight)
+	case counts[ranks[0]] == 3:
+		return newHandRank(THREE_OF_A_KIND, ranks...)
+	case counts[ranks[0]] == 2 && counts[ranks[1]] == 2:
+		return newHandRank(TWO_PAIR, ranks...)
+	case counts[ranks[0]] == 2:
+		return newHandRank(ONE_PAIR, ranks...)
+	default:
+		return newHandRank(HIGH_CARD, ranks...)
+	}
+}
@@ -0,0 +1,123 @@
+package nlhe
+
+import (
+	"testing"
+
+	Def "github.com/tam0705/go-cfr/def"
+)
+
+func TestEvaluate(t *testing.T) {
+	for _, tc := range []struct {
+		cards    string
+		category HandCategory
+	}{
+		{"As Ks Qs Js Ts 2c 3d", STRAIGHT_FLUSH},
+		{"Ah 2h 3h 4h 5h", STRAIGHT_FLUSH},
+		{"9c 9d 9h 9s 2c", FOUR_OF_A_KIND},
+		{"9c 9d 9h 2s 2c 2d", FULL_HOUSE},
+		{"2h 7h 9h Jh Kh Ac Ad", FLUSH},
+		{"Ac 2d 3h 4s 5c Kd", STRAIGHT},
+		{"Tc Jd Qh Ks Ac", STRAIGHT},
+		{"7c 7d 7h As 2c", THREE_OF_A_KIND},
+		{"7c 7d 2h 2s 3c 3d 9h", TWO_PAIR},
+		{"7c 7d 2h 4s 9c", ONE_PAIR},
+		{"7c 8d 2h 4s Kc Jd 3s", HIGH_CARD},
+		{"Qc Kd Ah 2s 3c", HIGH_CARD},
+	} {
+		if c := Evaluate(MustParseCards(tc.cards)...).Category(); c != tc.category {
+			t.Errorf("%s: expected %v, got %v", tc.cards, tc.category, c)
+		}
+	}
+}
+
+func TestEvaluateOrder(t *testing.T) {
+	// Each hand is stronger than the next.
+	hands := []string{
+		"As Ks Qs Js Ts",
+		"6h 2h 3h 4h 5h",
+		"Ah 2h 3h 4h 5h",
+		"Ac Ad Ah As Kc",
+		"Ac Ad Ah As Qc",
+		"3c 3d 3h Ac Ad",
+		"2c 2d 2h Ac Ad",
+		"Ah Qh 9h 8h 7h",
+		"Ah Qh 9h 8h 6h",
+		"Tc Jd Qh Ks Ac",
+		"6c 2d 3h 4s 5c",
+		"Ac 2d 3h 4s 5c",
+		"Qc Qd Qh 3s 2c",
+		"Ac Ad Kh Ks 2c",
+		"Ac Ad Qh Qs Kc",
+		"Ac Ad Qh Qs Jc",
+		"Ac Ad Kh Qs Jc",
+		"Kc Kd Ah Qs Jc",
+		"Ac Kd Qh Js 9c",
+		"Ac Kd Qh Js 8c",
+		"7c 5d 4h 3s 2c",
+	}
+	for i := 1; i < len(hands); i++ {
+		stronger := Evaluate(MustParseCards(hands[i-1])...)
+		weaker := Evaluate(MustParseCards(hands[i])...)
+		if stronger <= weaker {
+			t.Errorf("expected %s (%v) to beat %s (%v)", hands[i-1], stronger, hands[i], weaker)
+		}
+	}
+
+	// Suits do not matter, and neither do cards outside the best five.
+	for _, tc := range [][2]string{
+		{"Ac Ad Kh Ks 2c", "Ah As Kc Kd 2d"},
+		{"Ac Ad Kh Ks Qc 2d 3d", "Ac Ad Kh Ks Qd"},
+		{"7c 7d 2h 2s 3c 3d Kh", "7c 7d 3c 3d Kh"},
+		{"Tc Jd Qh Ks Ac 9c 8c", "Th Jh Qc Ks Ad"},
+	} {
+		a, b := Evaluate(MustParseCards(tc[0])...), Evaluate(MustParseCards(tc[1])...)
+		if a != b {
+			t.Errorf("expected %s and %s to tie, got %v and %v", tc[0], tc[1], a, b)
+		}
+	}
+}
+
+func TestEvaluateCards(t *testing.T) {
+	var cards Def.Cards
+	copy(cards[:], MustParseCards("As Ad Ah Kc Kd"))
+	if c := EvaluateCards(cards).Category(); c != FULL_HOUSE {
+		t.Errorf("expected %v, got %v", FULL_HOUSE, c)
+	}
+}
+
+func TestCategoryCounts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping exhaustive enumeration in short mode")
+	}
+
+	deck := NewDeck().cards
+	var counts [STRAIGHT_FLUSH + 1]int
+	var hand [5]Def.Poker
+	var enumerate func(next, k int)
+	enumerate = func(next, k int) {
+		if k == len(hand) {
+			counts[evaluate5(hand).Category()]++
+			return
+		}
+
+		for i := next; i < len(deck); i++ {
+			hand[k] = deck[i]
+			enumerate(i+1, k+1)
+		}
+	}
+	enumerate(0, 0)
+
+	want := [...]int{1302540, 1098240, 123552, 54912, 10200, 5108, 3744, 624, 40}
+	for c, n := range counts {
+		if n != want[c] {
+			t.Errorf("%v: expected %d hands, got %d", HandCategory(c), want[c], n)
+		}
+	}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	cards := MustParseCards("As Kd 7h 7c 2s Td 9d")
+	for i := 0; i < b.N; i++ {
+		Evaluate(cards...)
+	}
+}
@@ -0,0 +1,741 @@
+package nlhe
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	Def "github.com/tam0705/go-cfr/def"
+)
+
+const (
+	MIN_SEATS = 2
+	MAX_SEATS = 9
+)
+
+// Street is a betting round of a hand.
+type Street uint8
+
+const (
+	PREFLOP Street = iota
+	FLOP
+	TURN
+	RIVER
+	SHOWDOWN
+)
+
+var streetNames = [...]string{"preflop", "flop", "turn", "river", "showdown"}
+
+// String implements fmt.Stringer.
+func (s Street) String() string {
+	if int(s) < len(streetNames) {
+		return streetNames[s]
+	}
+
+	return fmt.Sprintf("Street(%d)", s)
+}
+
+// boardSize is the number of board cards dealt by the end of each street.
+var boardSize = [...]int{0, 3, 4, 5, 5}
+
+// Config configures a Hand.
+type Config struct {
+	SmallBlind int64
+	BigBlind   int64
+	Ante       int64
+	// Stacks are the chips of each seat at the start of the hand, which
+	// must all be positive. There must be between 2 and 9 seats.
+	Stacks []int64
+	// Button is the seat of the dealer button.
+	Button int
+	// HoleCards, if non-nil, are the hole cards of each seat. Seats whose
+	// hole cards are zero are dealt them from the deck.
+	HoleCards [][2]Def.Poker
+	// Board are the first board cards to deal, if any. The rest of the
+	// board is dealt from the deck.
+	Board []Def.Poker
+}
+
+// Action is an action taken by a seat.
+type Action struct {
+	Seat   int
+	Street Street
+	Action Def.PlayerAction
+	// Amount is the seat's total bet on the street after the action.
+	Amount int64
+}
+
+// Pot is the main pot or a side pot.
+type Pot struct {
+	Amount int64
+	// Eligible are the seats which have not folded and can win the pot.
+	Eligible []int
+}
+
+// Hand is a single hand of no-limit hold'em.
+type Hand struct {
+	bigBlind int64
+	button   int
+	deck     *Deck
+
+	holeCards [][2]Def.Poker
+	board     []Def.Poker
+	// presetBoard are the board cards given by the Config.
+	presetBoard []Def.Poker
+
+	// stacks are the chips each seat has behind, committed the chips
+	// each seat has put in the pot, and bets their bets on this street.
+	stacks    []int64
+	committed []int64
+	bets      []int64
+	folded    []bool
+	allIn     []bool
+	// acted is whether each seat has acted since the last full raise,
+	// and actedAt the current bet when they last did.
+	acted   []bool
+	actedAt []int64
+
+	street     Street
+	toAct      int
+	currentBet int64
+	minRaise   int64
+	over       bool
+
+	history []Action
+	pots    []Pot
+	payouts []int64
+}
+
+// NewHand starts a new hand with the given config, dealing cards from
+// the given deck. If deck is nil, a shuffled deck is used. The hole and
+// board cards given in the config are removed from the deck.
+//
+// The antes and blinds are posted, and the hole cards dealt, so the
+// hand is ready for the first action preflop.
+func NewHand(config Config, deck *Deck) (*Hand, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	if deck == nil {
+		deck = NewShuffledDeck(rand.New(rand.NewSource(rand.Int63())))
+	}
+	for _, cards := range config.HoleCards {
+		if cards != [2]Def.Poker{} {
+			deck.Remove(cards[:]...)
+		}
+	}
+	deck.Remove(config.Board...)
+
+	n := len(config.Stacks)
+	h := &Hand{
+		bigBlind:    config.BigBlind,
+		button:      config.Button,
+		deck:        deck,
+		holeCards:   make([][2]Def.Poker, n),
+		presetBoard: config.Board,
+		stacks:      append([]int64(nil), config.Stacks...),
+		committed:   make([]int64, n),
+		bets:        make([]int64, n),
+		folded:      make([]bool, n),
+		allIn:       make([]bool, n),
+		acted:       make([]bool, n),
+		actedAt:     make([]int64, n),
+		minRaise:    config.BigBlind,
+	}
+
+	// Deal one card at a time, starting from the seat after the button.
+	for c := 0; c < 2; c++ {
+		for i := 1; i <= n; i++ {
+			seat := h.seat(h.button + i)
+			if config.HoleCards != nil && config.HoleCards[seat] != [2]Def.Poker{} {
+				h.holeCards[seat] = config.HoleCards[seat]
+			} else {
+				h.holeCards[seat][c] = deck.Deal()
+			}
+		}
+	}
+
+	if config.Ante > 0 {
+		for seat := range h.stacks {
+			h.post(seat, config.Ante)
+		}
+		// Antes are dead, and do not count towards the bets.
+		for seat := range h.bets {
+			h.bets[seat] = 0
+		}
+	}
+
+	// Heads-up, the button posts the small blind.
+	sb := h.seat(h.button + 1)
+	if n == 2 {
+		sb = h.button
+	}
+	bb := h.seat(sb + 1)
+	h.post(sb, config.SmallBlind)
+	h.post(bb, config.BigBlind)
+	// The big blind must be called in full, even if it is all in for less.
+	h.currentBet = config.BigBlind
+
+	h.advance(bb + 1)
+	return h, nil
+}
+
+func (config *Config) validate() error {
+	n := len(config.Stacks)
+	if n < MIN_SEATS || n > MAX_SEATS {
+		return fmt.Errorf("nlhe: invalid number of seats: %d", n)
+	}
+
+	for seat, stack := range config.Stacks {
+		if stack <= 0 {
+			return fmt.Errorf("nlhe: seat %d has invalid stack %d", seat, stack)
+		}
+	}
+
+	if config.BigBlind <= 0 || config.SmallBlind < 0 || config.SmallBlind > config.BigBlind || config.Ante < 0 {
+		return fmt.Errorf("nlhe: invalid blinds %d/%d and ante %d",
+			config.SmallBlind, config.BigBlind, config.Ante)
+	}
+
+	if config.Button < 0 || config.Button >= n {
+		return fmt.Errorf("nlhe: invalid button: %d", config.Button)
+	}
+
+	if config.HoleCards != nil && len(config.HoleCards) != n {
+		return fmt.Errorf("nlhe: %d seats but hole cards for %d", n, len(config.HoleCards))
+	}
+
+	if len(config.Board) > 5 {
+		return fmt.Errorf("nlhe: board has %d cards", len(config.Board))
+	}
+
+	seen := make(map[Def.Poker]bool)
+	check := func(card Def.Poker) error {
+		if CardString(card) == "??" {
+			return fmt.Errorf("nlhe: invalid card: %+v", card)
+		} else if seen[card] {
+			return fmt.Errorf("nlhe: duplicate card: %s", CardString(card))
+		}
+
+		seen[card] = true
+		return nil
+	}
+
+	for _, cards := range config.HoleCards {
+		if cards == [2]Def.Poker{} {
+			continue
+		}
+
+		for _, card := range cards {
+			if err := check(card); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, card := range config.Board {
+		if err := check(card); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seat returns the seat i places clockwise from seat 0.
+func (h *Hand) seat(i int) int {
+	return i % len(h.stacks)
+}
+
+// post moves up to amount chips from the seat's stack to the pot.
+func (h *Hand) post(seat int, amount int64) {
+	if amount >= h.stacks[seat] {
+		amount = h.stacks[seat]
+		h.allIn[seat] = true
+	}
+
+	h.stacks[seat] -= amount
+	h.committed[seat] += amount
+	h.bets[seat] += amount
+}
+
+// canAct returns whether the seat can still take actions in the hand.
+func (h *Hand) canAct(seat int) bool {
+	return !h.folded[seat] && !h.allIn[seat]
+}
+
+// numCanAct returns the number of seats which can still take actions.
+func (h *Hand) numCanAct() int {
+	n := 0
+	for seat := range h.stacks {
+		if h.canAct(seat) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// numInHand returns the number of seats which have not folded.
+func (h *Hand) numInHand() int {
+	n := 0
+	for _, folded := range h.folded {
+		if !folded {
+			n++
+		}
+	}
+
+	return n
+}
+
+// needsAction returns whether the seat must act before the street ends.
+func (h *Hand) needsAction(seat int) bool {
+	if !h.canAct(seat) {
+		return false
+	}
+
+	// A seat that is the only one left to act need not act unless it
+	// faces a bet.
+	return h.bets[seat] < h.currentBet || (!h.acted[seat] && h.numCanAct() > 1)
+}
+
+// advance passes the action to the first seat clockwise from start
+// which needs to act, or else ends the street. Streets on which no seat
+// needs to act are dealt out until the showdown.
+func (h *Hand) advance(start int) {
+	for {
+		if h.numInHand() == 1 {
+			h.settle()
+			return
+		}
+
+		for i := 0; i < len(h.stacks); i++ {
+			if seat := h.seat(start + i); h.needsAction(seat) {
+				h.toAct = seat
+				return
+			}
+		}
+
+		if h.street == RIVER {
+			h.street = SHOWDOWN
+			h.settle()
+			return
+		}
+
+		h.nextStreet()
+		start = h.button + 1
+	}
+}
+
+// nextStreet ends the betting on the current street and deals the next.
+func (h *Hand) nextStreet() {
+	h.street++
+	h.currentBet = 0
+	h.minRaise = h.bigBlind
+	for seat := range h.bets {
+		h.bets[seat] = 0
+		h.acted[seat] = false
+		h.actedAt[seat] = 0
+	}
+
+	h.deck.Deal() // Burn a card.
+	for len(h.board) < boardSize[h.street] {
+		if len(h.board) < len(h.presetBoard) {
+			h.board = append(h.board, h.presetBoard[len(h.board)])
+		} else {
+			h.board = append(h.board, h.deck.Deal())
+		}
+	}
+}
+
+// canRaise returns whether the seat to act may raise, i.e. whether the
+// betting has been reopened by full raises since it last acted.
+func (h *Hand) canRaise() bool {
+	seat := h.toAct
+	for other := range h.stacks {
+		if other != seat && h.canAct(other) {
+			return !h.acted[seat] || h.currentBet-h.actedAt[seat] >= h.minRaise
+		}
+	}
+
+	// There is nobody left to call a raise.
+	return false
+}
+
+// LegalActions returns the actions that the seat to act may take, or 0
+// if the hand is over.
+func (h *Hand) LegalActions() Def.PlayerAction {
+	if h.over {
+		return 0
+	}
+
+	seat := h.toAct
+	toCall := h.currentBet - h.bets[seat]
+	var actions Def.PlayerAction
+	if toCall > 0 {
+		// Calling for less than the full amount puts the seat all in.
+		actions |= Def.PLAYER_ACTION_FOLD | Def.PLAYER_ACTION_CALL
+	} else {
+		actions |= Def.PLAYER_ACTION_CHECK
+	}
+
+	canRaise := h.canRaise()
+	if canRaise && h.bets[seat]+h.stacks[seat] > h.currentBet+h.minRaise {
+		actions |= Def.PLAYER_ACTION_RAISE
+	}
+
+	// Going all in is a call if it does not exceed the current bet, and
+	// otherwise a (possibly incomplete) raise.
+	if toCall >= h.stacks[seat] || canRaise {
+		actions |= Def.PLAYER_ACTION_ALLIN
+	}
+
+	return actions
+}
+
+// Act takes the given action for the seat to act. The amount is the total
+// bet on the street to raise to, and is ignored for other actions. Raising
+// to the seat's whole stack is going all in.
+func (h *Hand) Act(action Def.PlayerAction, amount int64) error {
+	if h.over {
+		return fmt.Errorf("nlhe: hand is over")
+	}
+
+	seat := h.toAct
+	if action == Def.PLAYER_ACTION_RAISE && amount == h.bets[seat]+h.stacks[seat] {
+		action = Def.PLAYER_ACTION_ALLIN
+	}
+
+	legal := h.LegalActions()
+	if action&legal == 0 || action&(action-1) != 0 {
+		return fmt.Errorf("nlhe: seat %d cannot take action %#x, legal actions are %#x", seat, action, legal)
+	}
+
+	switch action {
+	case Def.PLAYER_ACTION_FOLD:
+		h.folded[seat] = true
+	case Def.PLAYER_ACTION_CHECK:
+	case Def.PLAYER_ACTION_CALL:
+		h.post(seat, h.currentBet-h.bets[seat])
+	case Def.PLAYER_ACTION_RAISE:
+		if amount < h.MinRaise() || amount > h.MaxRaise() {
+			return fmt.Errorf("nlhe: seat %d cannot raise to %d, must raise to between %d and %d",
+				seat, amount, h.MinRaise(), h.MaxRaise())
+		}
+
+		h.raise(seat, amount)
+	case Def.PLAYER_ACTION_ALLIN:
+		h.raise(seat, h.bets[seat]+h.stacks[seat])
+	}
+
+	h.acted[seat] = true
+	h.actedAt[seat] = h.currentBet
+	h.history = append(h.history, Action{
+		Seat:   seat,
+		Street: h.street,
+		Action: action,
+		Amount: h.bets[seat],
+	})
+
+	h.advance(seat + 1)
+	return nil
+}
+
+// raise makes the seat's total bet on the street amount.
+func (h *Hand) raise(seat int, amount int64) {
+	h.post(seat, amount-h.bets[seat])
+	if amount <= h.currentBet {
+		return
+	}
+
+	// Only a full raise reopens the betting for seats that have acted.
+	if increment := amount - h.currentBet; increment >= h.minRaise {
+		h.minRaise = increment
+		for other := range h.acted {
+			h.acted[other] = false
+		}
+	}
+
+	h.currentBet = amount
+}
+
+// settle returns the uncalled part of the largest bet, and distributes
+// the pots among the winners.
+func (h *Hand) settle() {
+	h.over = true
+	h.toAct = -1
+	h.payouts = make([]int64, len(h.stacks))
+
+	top, second := h.topCommitted()
+	h.payouts[top] += h.committed[top] - second
+	h.pots = h.computePots(top, second)
+
+	var ranks []HandRank
+	if h.street == SHOWDOWN {
+		ranks = make([]HandRank, len(h.stacks))
+		for seat := range h.stacks {
+			if !h.folded[seat] {
+				ranks[seat] = EvaluateCards(h.Cards(seat))
+			}
+		}
+	}
+
+	for _, pot := range h.pots {
+		winners := pot.Eligible
+		if len(winners) > 1 {
+			var best HandRank
+			for _, seat := range pot.Eligible {
+				if ranks[seat] > best {
+					best = ranks[seat]
+				}
+			}
+
+			winners = nil
+			for _, seat := range pot.Eligible {
+				if ranks[seat] == best {
+					winners = append(winners, seat)
+				}
+			}
+		}
+
+		share := pot.Amount / int64(len(winners))
+		for _, seat := range winners {
+			h.payouts[seat] += share
+		}
+
+		// The odd chips go to the first winners clockwise from the button.
+		oddChips := pot.Amount % int64(len(winners))
+		for i := 1; oddChips > 0; i++ {
+			seat := h.seat(h.button + i)
+			for _, winner := range winners {
+				if winner == seat {
+					h.payouts[seat]++
+					oddChips--
+					break
+				}
+			}
+		}
+	}
+
+	for seat, payout := range h.payouts {
+		h.stacks[seat] += payout
+	}
+}
+
+// topCommitted returns the seat that committed the most chips, and the
+// largest commitment of the other seats. The difference has not been
+// called.
+func (h *Hand) topCommitted() (int, int64) {
+	top, second := 0, int64(0)
+	for seat, c := range h.committed[1:] {
+		seat++
+		if c > h.committed[top] {
+			top, second = seat, h.committed[top]
+		} else if c > second {
+			second = c
+		}
+	}
+
+	return top, second
+}
+
+// computePots returns the main pot and side pots, excluding the uncalled
+// part of the top seat's commitment above the limit.
+func (h *Hand) computePots(top int, limit int64) []Pot {
+	committed := append([]int64(nil), h.committed...)
+	if committed[top] > limit {
+		committed[top] = limit
+	}
+
+	var levels []int64
+	for seat, c := range committed {
+		if !h.folded[seat] {
+			levels = append(levels, c)
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+
+	var pots []Pot
+	var prev int64
+	for _, level := range levels {
+		if level == prev {
+			continue
+		}
+
+		pot := Pot{}
+		for seat, c := range committed {
+			pot.Amount += min64(c, level) - min64(c, prev)
+			if !h.folded[seat] && c >= level {
+				pot.Eligible = append(pot.Eligible, seat)
+			}
+		}
+
+		pots = append(pots, pot)
+		prev = level
+	}
+
+	if len(pots) == 0 {
+		pots = append(pots, Pot{})
+	}
+
+	// Chips that folded seats committed above every other seat go to
+	// the last pot.
+	for _, c := range committed {
+		if c > prev {
+			pots[len(pots)-1].Amount += c - prev
+		}
+	}
+
+	return pots
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// NumSeats returns the number of seats in the hand.
+func (h *Hand) NumSeats() int {
+	return len(h.stacks)
+}
+
+// Button returns the seat of the dealer button.
+func (h *Hand) Button() int {
+	return h.button
+}
+
+// Street returns the current street, which is SHOWDOWN once the hand
+// has been settled at a showdown.
+func (h *Hand) Street() Street {
+	return h.street
+}
+
+// IsOver returns whether the hand is over.
+func (h *Hand) IsOver() bool {
+	return h.over
+}
+
+// ToAct returns the seat to act, or -1 if the hand is over.
+func (h *Hand) ToAct() int {
+	return h.toAct
+}
+
+// CurrentBet returns the bet to call on the current street.
+func (h *Hand) CurrentBet() int64 {
+	return h.currentBet
+}
+
+// CallAmount returns the number of chips the seat to act must add to
+// call, which is limited by its stack.
+func (h *Hand) CallAmount() int64 {
+	if h.over {
+		return 0
+	}
+
+	return min64(h.currentBet-h.bets[h.toAct], h.stacks[h.toAct])
+}
+
+// MinRaise returns the smallest total bet that the seat to act may raise to.
+func (h *Hand) MinRaise() int64 {
+	return h.currentBet + h.minRaise
+}
+
+// MaxRaise returns the largest total bet that the seat to act may raise
+// to, which is going all in.
+func (h *Hand) MaxRaise() int64 {
+	if h.over {
+		return 0
+	}
+
+	return h.bets[h.toAct] + h.stacks[h.toAct]
+}
+
+// Stack returns the chips the seat has behind. Once the hand is over,
+// that includes its winnings.
+func (h *Hand) Stack(seat int) int64 {
+	return h.stacks[seat]
+}
+
+// Bet returns the seat's total bet on the current street.
+func (h *Hand) Bet(seat int) int64 {
+	return h.bets[seat]
+}
+
+// Committed returns the total chips the seat has put in the pot.
+func (h *Hand) Committed(seat int) int64 {
+	return h.committed[seat]
+}
+
+// Folded returns whether the seat has folded.
+func (h *Hand) Folded(seat int) bool {
+	return h.folded[seat]
+}
+
+// AllIn returns whether the seat is all in.
+func (h *Hand) AllIn(seat int) bool {
+	return h.allIn[seat]
+}
+
+// HoleCards returns the seat's hole cards.
+func (h *Hand) HoleCards(seat int) [2]Def.Poker {
+	return h.holeCards[seat]
+}
+
+// Board returns the board cards dealt so far.
+func (h *Hand) Board() []Def.Poker {
+	return h.board
+}
+
+// Cards returns the seat's hole cards and the board cards dealt so far.
+// Board cards that have not been dealt are zero.
+func (h *Hand) Cards(seat int) Def.Cards {
+	var cards Def.Cards
+	cards[0], cards[1] = h.holeCards[seat][0], h.holeCards[seat][1]
+	copy(cards[2:], h.board)
+	return cards
+}
+
+// History returns the actions taken so far.
+func (h *Hand) History() []Action {
+	return h.history
+}
+
+// Pot returns the total chips in the pot.
+func (h *Hand) Pot() int64 {
+	var total int64
+	for _, c := range h.committed {
+		total += c
+	}
+
+	return total
+}
+
+// Pots returns the main pot followed by the side pots. Before the hand
+// is over, these are the pots if the betting ended now, without any
+// bet that has not been called.
+func (h *Hand) Pots() []Pot {
+	if h.over {
+		return h.pots
+	}
+
+	return h.computePots(h.topCommitted())
+}
+
+// Results returns the net chips won or lost by each seat, or nil if the
+// hand is not over.
+func (h *Hand) Results() []int64 {
+	if !h.over {
+		return nil
+	}
+
+	results := make([]int64, len(h.stacks))
+	for seat := range results {
+		results[seat] = h.payouts[seat] - h.committed[seat]
+	}
+
+	return results
+}
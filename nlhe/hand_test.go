@@ -0,0 +1,378 @@
+package nlhe
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	Def "github.com/tam0705/go-cfr/def"
+)
+
+// step is an action taken in a replayed hand.
+type step struct {
+	action Def.PlayerAction
+	amount int64
+}
+
+var (
+	fold  = step{action: Def.PLAYER_ACTION_FOLD}
+	check = step{action: Def.PLAYER_ACTION_CHECK}
+	call  = step{action: Def.PLAYER_ACTION_CALL}
+	allIn = step{action: Def.PLAYER_ACTION_ALLIN}
+)
+
+func raise(amount int64) step {
+	return step{Def.PLAYER_ACTION_RAISE, amount}
+}
+
+// holeCards parses the hole cards of each seat, e.g. "As Ad", "", "Kc Kd".
+func holeCards(seats ...string) [][2]Def.Poker {
+	result := make([][2]Def.Poker, len(seats))
+	for i, s := range seats {
+		if s != "" {
+			copy(result[i][:], MustParseCards(s))
+		}
+	}
+
+	return result
+}
+
+// replay takes the given actions, checking that each is taken by the
+// expected seat.
+func replay(t *testing.T, h *Hand, seats []int, steps ...step) {
+	t.Helper()
+	for i, s := range steps {
+		if h.ToAct() != seats[i] {
+			t.Fatalf("step %d: expected seat %d to act, got %d", i, seats[i], h.ToAct())
+		}
+
+		if err := h.Act(s.action, s.amount); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+	}
+}
+
+func checkResults(t *testing.T, h *Hand, want ...int64) {
+	t.Helper()
+	if !h.IsOver() {
+		t.Fatalf("expected hand to be over, seat %d to act on the %v", h.ToAct(), h.Street())
+	}
+
+	if results := h.Results(); !reflect.DeepEqual(results, want) {
+		t.Errorf("expected results %v, got %v", want, results)
+	}
+}
+
+func TestHeadsUp(t *testing.T) {
+	h, err := NewHand(Config{
+		SmallBlind: 5,
+		BigBlind:   10,
+		Stacks:     []int64{1000, 1000},
+		Button:     0,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The button posts the small blind and acts first preflop.
+	if h.Bet(0) != 5 || h.Bet(1) != 10 {
+		t.Errorf("expected blinds 5 and 10, got %d and %d", h.Bet(0), h.Bet(1))
+	}
+
+	replay(t, h, []int{0, 1}, raise(30), call)
+	if h.Street() != FLOP || len(h.Board()) != 3 || h.Pot() != 60 {
+		t.Errorf("expected flop with pot 60, got %v with pot %d and board %s",
+			h.Street(), h.Pot(), CardsString(h.Board()))
+	}
+
+	// The big blind acts first after the flop. The uncalled bet is returned.
+	replay(t, h, []int{1, 0, 1}, check, raise(40), fold)
+	checkResults(t, h, 30, -30)
+	if h.Stack(0) != 1030 || h.Stack(1) != 970 {
+		t.Errorf("expected stacks 1030 and 970, got %d and %d", h.Stack(0), h.Stack(1))
+	}
+	if pots := h.Pots(); len(pots) != 1 || pots[0].Amount != 60 {
+		t.Errorf("expected a single pot of 60, got %+v", pots)
+	}
+}
+
+func TestSidePots(t *testing.T) {
+	config := Config{
+		SmallBlind: 5,
+		BigBlind:   10,
+		Stacks:     []int64{100, 300, 500},
+		Button:     0,
+		HoleCards:  holeCards("As Ah", "Ks Kh", "Qs Qh"),
+		Board:      MustParseCards("2c 7d 9h Jc 3s"),
+	}
+	h, err := NewHand(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Once nobody else can act, the board is run out.
+	replay(t, h, []int{0, 1, 2}, allIn, allIn, call)
+	if h.Street() != SHOWDOWN || CardsString(h.Board()) != "2c 7d 9h Jc 3s" {
+		t.Errorf("expected showdown on the preset board, got %v with %s", h.Street(), CardsString(h.Board()))
+	}
+
+	checkResults(t, h, 200, 100, -300)
+	want := []Pot{{300, []int{0, 1, 2}}, {400, []int{1, 2}}}
+	if pots := h.Pots(); !reflect.DeepEqual(pots, want) {
+		t.Errorf("expected pots %+v, got %+v", want, pots)
+	}
+
+	// The big stack wins both pots when the board gives it a set.
+	config.Board = MustParseCards("Qd 7d 9h Jc 3s")
+	h, err = NewHand(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replay(t, h, []int{0, 1, 2}, allIn, allIn, call)
+	checkResults(t, h, -100, -300, 400)
+	if h.Stack(2) != 900 {
+		t.Errorf("expected stack 900, got %d", h.Stack(2))
+	}
+}
+
+func TestSplitPot(t *testing.T) {
+	h, err := NewHand(Config{
+		SmallBlind: 5,
+		BigBlind:   10,
+		Stacks:     []int64{1000, 1000, 1000},
+		Button:     0,
+		HoleCards:  holeCards("2c 3d", "", "4h 5s"),
+		Board:      MustParseCards("9c Td Jh Qs Kc"),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replay(t, h, []int{0, 1, 2}, call, fold, check)
+	for street := FLOP; street <= RIVER; street++ {
+		replay(t, h, []int{2, 0}, check, check)
+	}
+
+	// Both play the board. The odd chip of the pot of 25 goes to the
+	// first winner after the button.
+	checkResults(t, h, 2, -5, 3)
+}
+
+func TestMinRaise(t *testing.T) {
+	h, err := NewHand(Config{
+		SmallBlind: 5,
+		BigBlind:   10,
+		Stacks:     []int64{1000, 1000},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h.MinRaise() != 20 || h.MaxRaise() != 1000 || h.CallAmount() != 5 {
+		t.Errorf("expected raise to between 20 and 1000 and call 5, got %d, %d and %d",
+			h.MinRaise(), h.MaxRaise(), h.CallAmount())
+	}
+
+	for _, s := range []step{raise(15), raise(1001), check} {
+		if err := h.Act(s.action, s.amount); err == nil {
+			t.Errorf("expected %+v to be illegal", s)
+		}
+	}
+
+	replay(t, h, []int{0}, raise(20))
+	if err := h.Act(Def.PLAYER_ACTION_RAISE, 25); err == nil {
+		t.Error("expected raise to 25 to be illegal")
+	}
+
+	// A raise of 20 makes the next minimum raise 20 more.
+	replay(t, h, []int{1}, raise(40))
+	if h.MinRaise() != 60 {
+		t.Errorf("expected minimum raise to 60, got %d", h.MinRaise())
+	}
+
+	replay(t, h, []int{0}, call)
+	legal := h.LegalActions()
+	if legal != Def.PLAYER_ACTION_CHECK|Def.PLAYER_ACTION_RAISE|Def.PLAYER_ACTION_ALLIN {
+		t.Errorf("unexpected legal actions %#x", legal)
+	}
+
+	// The minimum bet after the flop is the big blind.
+	if h.MinRaise() != 10 {
+		t.Errorf("expected minimum bet 10, got %d", h.MinRaise())
+	}
+
+	// Raising to the whole stack is going all in.
+	replay(t, h, []int{1, 0}, raise(960), call)
+	if !h.AllIn(0) || !h.AllIn(1) || h.Street() != SHOWDOWN {
+		t.Errorf("expected both all in at showdown, got %v", h.Street())
+	}
+}
+
+func TestIncompleteRaise(t *testing.T) {
+	h, err := NewHand(Config{
+		SmallBlind: 5,
+		BigBlind:   10,
+		Stacks:     []int64{1000, 150, 1000},
+		Button:     0,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The small blind's all in to 150 is less than a full raise, so it
+	// does not reopen the betting for the button, but the big blind, who
+	// has not acted yet, may still raise.
+	replay(t, h, []int{0, 1}, raise(100), allIn)
+	if h.LegalActions()&Def.PLAYER_ACTION_RAISE == 0 {
+		t.Error("expected the big blind to be able to raise")
+	}
+
+	replay(t, h, []int{2}, call)
+	if legal := h.LegalActions(); legal != Def.PLAYER_ACTION_FOLD|Def.PLAYER_ACTION_CALL {
+		t.Errorf("expected the button to only be able to call or fold, got %#x", legal)
+	}
+	if err := h.Act(Def.PLAYER_ACTION_RAISE, 300); err == nil {
+		t.Error("expected raise to be illegal")
+	}
+
+	replay(t, h, []int{0}, call)
+	if h.Street() != FLOP || h.Pot() != 450 {
+		t.Errorf("expected flop with pot 450, got %v with pot %d", h.Street(), h.Pot())
+	}
+
+	// The all-in seat is skipped.
+	replay(t, h, []int{2, 0}, raise(50), raise(150))
+	want := []Pot{{450, []int{0, 1, 2}}, {100, []int{0, 2}}}
+	if pots := h.Pots(); !reflect.DeepEqual(pots, want) {
+		t.Errorf("expected pots %+v, got %+v", want, pots)
+	}
+
+	// The button's raise is returned, and it goes to showdown with the
+	// all-in seat for the main pot.
+	replay(t, h, []int{2}, fold)
+	want = []Pot{{450, []int{0, 1}}, {100, []int{0}}}
+	if pots := h.Pots(); !reflect.DeepEqual(pots, want) {
+		t.Errorf("expected pots %+v, got %+v", want, pots)
+	}
+	if h.Street() != SHOWDOWN || h.Results()[2] != -200 {
+		t.Errorf("expected showdown with seat 2 losing 200, got %v with results %v", h.Street(), h.Results())
+	}
+}
+
+func TestAntes(t *testing.T) {
+	h, err := NewHand(Config{
+		SmallBlind: 5,
+		BigBlind:   10,
+		Ante:       1,
+		Stacks:     []int64{100, 100, 6, 100},
+		Button:     0,
+		HoleCards:  holeCards("", "Ks Kd", "As Ad", "Qs Qd"),
+		Board:      MustParseCards("2c 7h 9s 3c 4d"),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The big blind is all in for 5 after the ante, but the others must
+	// call the full big blind.
+	if !h.AllIn(2) || h.Bet(2) != 5 || h.CallAmount() != 10 {
+		t.Errorf("expected big blind all in for 5 and call 10, got %d and %d", h.Bet(2), h.CallAmount())
+	}
+
+	replay(t, h, []int{3, 0, 1}, call, fold, call)
+	want := []Pot{{19, []int{1, 2, 3}}, {10, []int{1, 3}}}
+	if pots := h.Pots(); !reflect.DeepEqual(pots, want) {
+		t.Errorf("expected pots %+v, got %+v", want, pots)
+	}
+
+	for street := FLOP; street <= RIVER; street++ {
+		replay(t, h, []int{1, 3}, check, check)
+	}
+
+	checkResults(t, h, -1, -1, 13, -11)
+}
+
+func TestNewHandErrors(t *testing.T) {
+	for _, config := range []Config{
+		{SmallBlind: 5, BigBlind: 10, Stacks: []int64{100}},
+		{SmallBlind: 5, BigBlind: 10, Stacks: make([]int64, 10)},
+		{SmallBlind: 5, BigBlind: 10, Stacks: []int64{100, 0}},
+		{SmallBlind: 20, BigBlind: 10, Stacks: []int64{100, 100}},
+		{SmallBlind: 5, BigBlind: 10, Stacks: []int64{100, 100}, Button: 2},
+		{SmallBlind: 5, BigBlind: 10, Stacks: []int64{100, 100}, HoleCards: holeCards("As Ad")},
+		{SmallBlind: 5, BigBlind: 10, Stacks: []int64{100, 100}, HoleCards: holeCards("As Ad", "As Kd")},
+		{SmallBlind: 5, BigBlind: 10, Stacks: []int64{100, 100}, Board: MustParseCards("2c 3c 4c 5c 6c 7c")},
+	} {
+		if _, err := NewHand(config, nil); err == nil {
+			t.Errorf("%+v: expected error", config)
+		}
+	}
+}
+
+// TestRandomHands plays hands with random legal actions, and checks that
+// no chips are created or lost.
+func TestRandomHands(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	actions := []Def.PlayerAction{
+		Def.PLAYER_ACTION_FOLD, Def.PLAYER_ACTION_CHECK, Def.PLAYER_ACTION_CALL,
+		Def.PLAYER_ACTION_RAISE, Def.PLAYER_ACTION_ALLIN,
+	}
+
+	for i := 0; i < 2000; i++ {
+		n := MIN_SEATS + rng.Intn(MAX_SEATS-MIN_SEATS+1)
+		config := Config{
+			SmallBlind: 1,
+			BigBlind:   2,
+			Ante:       int64(rng.Intn(2)),
+			Stacks:     make([]int64, n),
+			Button:     rng.Intn(n),
+		}
+		var total int64
+		for seat := range config.Stacks {
+			config.Stacks[seat] = 1 + rng.Int63n(200)
+			total += config.Stacks[seat]
+		}
+
+		h, err := NewHand(config, NewShuffledDeck(rng))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for !h.IsOver() {
+			legal := h.LegalActions()
+			action := actions[rng.Intn(len(actions))]
+			if legal&action == 0 {
+				continue
+			}
+
+			amount := h.MinRaise()
+			if action == Def.PLAYER_ACTION_RAISE {
+				amount += rng.Int63n(h.MaxRaise() - amount)
+			}
+
+			if err := h.Act(action, amount); err != nil {
+				t.Fatalf("hand %d: %v", i, err)
+			}
+		}
+
+		var sum, stacks int64
+		for seat, result := range h.Results() {
+			sum += result
+			stacks += h.Stack(seat)
+			if h.Stack(seat) < 0 {
+				t.Errorf("hand %d: seat %d has negative stack %d", i, seat, h.Stack(seat))
+			}
+		}
+
+		var pots int64
+		for _, pot := range h.Pots() {
+			pots += pot.Amount
+		}
+
+		if sum != 0 || stacks != total || pots > h.Pot() {
+			t.Fatalf("hand %d: results %v sum to %d, stacks to %d of %d, pots %+v",
+				i, h.Results(), sum, stacks, total, h.Pots())
+		}
+	}
+}
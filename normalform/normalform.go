@@ -0,0 +1,325 @@
+// Package normalform represents normal-form (matrix) games as extensive-form
+// game trees, for quick sanity checks of CFR variants.
+//
+// The players move in turn, but none of them observes the earlier moves,
+// so each player has a single InfoSet and the moves are effectively
+// simultaneous.
+package normalform
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math/rand"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// Game is an N-player normal-form game.
+type Game struct {
+	// actions is the number of actions of each player.
+	actions []int
+	// payoffs holds the payoff of each player for each joint action,
+	// in row-major order of the players' actions.
+	payoffs []float64
+}
+
+// NewGame returns a game in which each player i has actions[i] actions.
+// The payoffs tensor holds the payoff of each player for each joint action
+// (a_0, ..., a_{n-1}), in row-major order: the payoff of player p is at
+// index (a_0*actions[1]*...*actions[n-1] + ... + a_{n-1})*n + p.
+func NewGame(actions []int, payoffs []float64) *Game {
+	size := len(actions)
+	for _, n := range actions {
+		if n < 1 {
+			panic(fmt.Errorf("normalform: invalid number of actions: %v", actions))
+		}
+		size *= n
+	}
+
+	if len(actions) == 0 || len(payoffs) != size {
+		panic(fmt.Errorf("normalform: expected %d payoffs for actions %v, got %d",
+			size, actions, len(payoffs)))
+	}
+
+	return &Game{
+		actions: append([]int(nil), actions...),
+		payoffs: append([]float64(nil), payoffs...),
+	}
+}
+
+// NewBimatrix returns the two-player game in which the row player (player 0)
+// receives a[i][j] and the column player (player 1) receives b[i][j]
+// when they play actions i and j.
+func NewBimatrix(a, b [][]float64) *Game {
+	rows, cols := len(a), 0
+	if rows > 0 {
+		cols = len(a[0])
+	}
+
+	payoffs := make([]float64, 0, 2*rows*cols)
+	for i := range a {
+		if len(a[i]) != cols || len(b) != rows || len(b[i]) != cols {
+			panic(fmt.Errorf("normalform: payoff matrices are not both %dx%d", rows, cols))
+		}
+
+		for j := range a[i] {
+			payoffs = append(payoffs, a[i][j], b[i][j])
+		}
+	}
+
+	return NewGame([]int{rows, cols}, payoffs)
+}
+
+// NewZeroSum returns the two-player zero-sum game in which the row player
+// receives a[i][j], and the column player -a[i][j].
+func NewZeroSum(a [][]float64) *Game {
+	b := make([][]float64, len(a))
+	for i := range a {
+		b[i] = make([]float64, len(a[i]))
+		for j, x := range a[i] {
+			b[i][j] = -x
+		}
+	}
+
+	return NewBimatrix(a, b)
+}
+
+// RockPaperScissors returns the game of rock-paper-scissors. Its unique
+// equilibrium is for both players to play each action with probability 1/3.
+func RockPaperScissors() *Game {
+	return NewZeroSum([][]float64{
+		{0, -1, 1},
+		{1, 0, -1},
+		{-1, 1, 0},
+	})
+}
+
+// MatchingPennies returns the game of matching pennies, which the row
+// player wins if both pennies match. Its unique equilibrium is for
+// both players to play each action with probability 1/2.
+func MatchingPennies() *Game {
+	return NewZeroSum([][]float64{
+		{1, -1},
+		{-1, 1},
+	})
+}
+
+// RandomBimatrix returns a rows x cols game with payoffs drawn
+// uniformly from [-1, 1).
+func RandomBimatrix(rng *rand.Rand, rows, cols int) *Game {
+	a, b := randomMatrix(rng, rows, cols), randomMatrix(rng, rows, cols)
+	return NewBimatrix(a, b)
+}
+
+// RandomZeroSum returns a rows x cols zero-sum game with payoffs
+// drawn uniformly from [-1, 1).
+func RandomZeroSum(rng *rand.Rand, rows, cols int) *Game {
+	return NewZeroSum(randomMatrix(rng, rows, cols))
+}
+
+func randomMatrix(rng *rand.Rand, rows, cols int) [][]float64 {
+	m := make([][]float64, rows)
+	for i := range m {
+		m[i] = make([]float64, cols)
+		for j := range m[i] {
+			m[i][j] = 2*rng.Float64() - 1
+		}
+	}
+
+	return m
+}
+
+// NumPlayers returns the number of players.
+func (g *Game) NumPlayers() int {
+	return len(g.actions)
+}
+
+// NumActions returns the number of actions of the given player.
+func (g *Game) NumActions(player int) int {
+	return g.actions[player]
+}
+
+// profileIndex returns the row-major index of the given joint action.
+func (g *Game) profileIndex(actions []int) int {
+	idx := 0
+	for i, a := range actions {
+		idx = idx*g.actions[i] + a
+	}
+
+	return idx
+}
+
+// Payoff returns the payoff of the given player when
+// the players play the given joint action.
+func (g *Game) Payoff(actions []int, player int) float64 {
+	return g.payoffs[g.profileIndex(actions)*g.NumPlayers()+player]
+}
+
+// ExpectedPayoffs returns the expected payoff of each player when each
+// player i plays the mixed strategy strategies[i].
+func (g *Game) ExpectedPayoffs(strategies [][]float32) []float64 {
+	nPlayers := g.NumPlayers()
+	result := make([]float64, nPlayers)
+	actions := make([]int, nPlayers)
+	for profile := 0; profile < len(g.payoffs)/nPlayers; profile++ {
+		// Decode the joint action, with the last player's action varying fastest.
+		p := 1.0
+		idx := profile
+		for i := nPlayers - 1; i >= 0; i-- {
+			actions[i] = idx % g.actions[i]
+			idx /= g.actions[i]
+			p *= float64(strategies[i][actions[i]])
+		}
+
+		for player := range result {
+			result[player] += p * g.payoffs[profile*nPlayers+player]
+		}
+	}
+
+	return result
+}
+
+// Root returns the root of the game tree, at which player 0 moves.
+func (g *Game) Root() *NormalFormNode {
+	return &NormalFormNode{game: g}
+}
+
+// NormalFormNode implements cfr.GameTreeNode for a normal-form game.
+type NormalFormNode struct {
+	parent   *NormalFormNode
+	game     *Game
+	player   int
+	children []NormalFormNode
+	// profile is the row-major index of the actions taken so far.
+	profile int
+}
+
+// String implements fmt.Stringer.
+func (k *NormalFormNode) String() string {
+	return fmt.Sprintf("Player %v's turn. Profile: %v", k.player, k.profile)
+}
+
+// GetNode implements cfr.GameTreeNode. Nodes are not addressable
+// by a history string, so it always returns nil.
+func (k *NormalFormNode) GetNode(history string) cfr.GameTreeNode {
+	return nil
+}
+
+// Close implements cfr.GameTreeNode.
+func (k *NormalFormNode) Close() {
+	k.children = nil
+}
+
+// Type implements cfr.GameTreeNode.
+func (k *NormalFormNode) Type() cfr.NodeType {
+	if k.player == k.game.NumPlayers() {
+		return cfr.TerminalNodeType
+	}
+
+	return cfr.PlayerNodeType
+}
+
+// NumChildren implements cfr.GameTreeNode.
+func (k *NormalFormNode) NumChildren() int {
+	if k.Type() == cfr.TerminalNodeType {
+		return 0
+	}
+
+	return k.game.actions[k.player]
+}
+
+// GetChild implements cfr.GameTreeNode.
+func (k *NormalFormNode) GetChild(i int) cfr.GameTreeNode {
+	if k.children == nil {
+		k.buildChildren()
+	}
+
+	return &k.children[i]
+}
+
+// Parent implements cfr.GameTreeNode.
+func (k *NormalFormNode) Parent() cfr.GameTreeNode {
+	if k.parent == nil {
+		return nil
+	}
+
+	return k.parent
+}
+
+// GetChildProbability implements cfr.GameTreeNode. There are no chance nodes.
+func (k *NormalFormNode) GetChildProbability(i int) float64 {
+	return 0.0
+}
+
+// SampleChild implements cfr.GameTreeNode. There are no chance nodes.
+func (k *NormalFormNode) SampleChild() (cfr.GameTreeNode, float64) {
+	panic("normalform: cannot sample from non-chance node")
+}
+
+// Player implements cfr.GameTreeNode.
+func (k *NormalFormNode) Player() int {
+	return k.player
+}
+
+// Utility implements cfr.GameTreeNode.
+func (k *NormalFormNode) Utility(player int) float64 {
+	return k.game.payoffs[k.profile*k.game.NumPlayers()+player]
+}
+
+// InfoSet implements cfr.GameTreeNode. Each player has a single
+// InfoSet, since they observe none of the other players' actions.
+func (k *NormalFormNode) InfoSet(player int) cfr.InfoSet {
+	return &InfoSet{Player: player}
+}
+
+// InfoSetKey implements cfr.GameTreeNode.
+func (k *NormalFormNode) InfoSetKey(player int) []byte {
+	return k.InfoSet(player).Key()
+}
+
+// LegalActions implements cfr.GameTreeNode. All actions are always legal.
+func (k *NormalFormNode) LegalActions() []bool {
+	return nil
+}
+
+func (k *NormalFormNode) buildChildren() {
+	n := k.NumChildren()
+	k.children = make([]NormalFormNode, n)
+	for i := range k.children {
+		k.children[i] = NormalFormNode{
+			parent:  k,
+			game:    k.game,
+			player:  k.player + 1,
+			profile: k.profile*n + i,
+		}
+	}
+}
+
+// InfoSet is the single InfoSet of a player.
+type InfoSet struct {
+	Player int
+}
+
+// Key implements cfr.InfoSet.
+func (is *InfoSet) Key() []byte {
+	return []byte{byte(is.Player)}
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (is *InfoSet) MarshalBinary() ([]byte, error) {
+	return is.Key(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (is *InfoSet) UnmarshalBinary(buf []byte) error {
+	if len(buf) != 1 {
+		return fmt.Errorf("normalform: invalid InfoSet: %v", buf)
+	}
+
+	is.Player = int(buf[0])
+	return nil
+}
+
+func init() {
+	gob.Register(&InfoSet{})
+}
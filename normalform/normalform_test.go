@@ -0,0 +1,178 @@
+package normalform
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+	"github.com/tam0705/go-cfr/sampling"
+	"github.com/tam0705/go-cfr/tree"
+)
+
+func TestGameTree(t *testing.T) {
+	root := RockPaperScissors().Root()
+	if n := tree.CountNodes(root); n != 1+3+9 {
+		t.Errorf("expected 13 nodes, got %d", n)
+	}
+	if n := tree.CountTerminalNodes(root); n != 9 {
+		t.Errorf("expected 9 terminal nodes, got %d", n)
+	}
+	if n := tree.CountInfoSets(root); n != 2 {
+		t.Errorf("expected 2 infosets, got %d", n)
+	}
+
+	for _, game := range []*Game{
+		RockPaperScissors(),
+		MatchingPennies(),
+		RandomBimatrix(rand.New(rand.NewSource(1)), 3, 5),
+		NewGame([]int{2, 3, 2}, make([]float64, 3*12)),
+	} {
+		if problems := tree.Validate(game.Root()); len(problems) > 0 {
+			t.Errorf("invalid game tree: %v", problems)
+		}
+	}
+}
+
+func TestPayoffs(t *testing.T) {
+	actions := []int{2, 3, 2}
+	payoffs := make([]float64, 3*12)
+	for i := range payoffs {
+		payoffs[i] = float64(i)
+	}
+
+	game := NewGame(actions, payoffs)
+	var node cfr.GameTreeNode = game.Root()
+	profile := []int{1, 2, 0}
+	for _, a := range profile {
+		node = node.GetChild(a)
+	}
+
+	// The joint action (1, 2, 0) has index 1*6 + 2*2 + 0 = 10.
+	for player := 0; player < 3; player++ {
+		want := float64(10*3 + player)
+		if u := node.Utility(player); u != want {
+			t.Errorf("expected utility %v for player %d, got %v", want, player, u)
+		}
+		if u := game.Payoff(profile, player); u != want {
+			t.Errorf("expected payoff %v for player %d, got %v", want, player, u)
+		}
+	}
+
+	pure := [][]float32{{0, 1}, {0, 0, 1}, {1, 0}}
+	if u := game.ExpectedPayoffs(pure); u[0] != 30 || u[2] != 32 {
+		t.Errorf("unexpected expected payoffs for a pure strategy profile: %v", u)
+	}
+
+	// The column player receives b[i][j].
+	bimatrix := NewBimatrix([][]float64{{1, 2}, {3, 4}}, [][]float64{{5, 6}, {7, 8}})
+	if u := bimatrix.Payoff([]int{1, 0}, 1); u != 7 {
+		t.Errorf("expected payoff 7, got %v", u)
+	}
+}
+
+// averageStrategies runs MCCFR on a two-player zero-sum game
+// and returns each player's average strategy.
+func averageStrategies(game *Game, sampler cfr.Sampler, iterations int) [][]float32 {
+	profile := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewMCCFR(profile, sampler)
+	root := game.Root()
+	for i := 0; i < iterations; i++ {
+		opt.Run(root)
+		profile.Update()
+	}
+
+	strategies := make([][]float32, game.NumPlayers())
+	for player := range strategies {
+		is := InfoSet{Player: player}
+		policy, ok := profile.GetPolicyByKey(string(is.Key()))
+		if !ok {
+			panic("no policy for player")
+		}
+		strategies[player] = policy.GetAverageStrategy()
+	}
+
+	return strategies
+}
+
+func TestMCCFR(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		game *Game
+		want [][]float32
+	}{
+		{"rock-paper-scissors", RockPaperScissors(), [][]float32{{1. / 3, 1. / 3, 1. / 3}, {1. / 3, 1. / 3, 1. / 3}}},
+		{"matching pennies", MatchingPennies(), [][]float32{{0.5, 0.5}, {0.5, 0.5}}},
+		// Each player is indifferent when the other plays heads with probability 2/5.
+		{"biased pennies", NewZeroSum([][]float64{{2, -1}, {-1, 1}}), [][]float32{{0.4, 0.6}, {0.4, 0.6}}},
+		// The row player's second action is strictly dominated by the first.
+		{"dominated", NewZeroSum([][]float64{{3, -1}, {2, -2}, {-1, 1}}), [][]float32{{1. / 3, 0, 2. / 3}, {1. / 3, 2. / 3}}},
+	} {
+		for _, sampler := range []cfr.Sampler{
+			sampling.NewExternalSampler(),
+			sampling.NewOutcomeSampler(0.6),
+		} {
+			strategies := averageStrategies(tc.game, sampler, 50000)
+			for player, strat := range strategies {
+				for i, p := range strat {
+					if math.Abs(float64(p-tc.want[player][i])) > 0.02 {
+						t.Errorf("%s, %T: expected player %d's average strategy %v, got %v",
+							tc.name, sampler, player, tc.want[player], strat)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+// exploitability returns the sum of what each player of a two-player game
+// could gain by deviating to a best response.
+func exploitability(game *Game, strategies [][]float32) float64 {
+	values := game.ExpectedPayoffs(strategies)
+	var total float64
+	for player := 0; player < 2; player++ {
+		best := math.Inf(-1)
+		for a := 0; a < game.NumActions(player); a++ {
+			deviation := append([][]float32(nil), strategies...)
+			deviation[player] = make([]float32, game.NumActions(player))
+			deviation[player][a] = 1
+			best = math.Max(best, game.ExpectedPayoffs(deviation)[player])
+		}
+
+		total += best - values[player]
+	}
+
+	return total
+}
+
+func TestRandomZeroSum(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < 5; i++ {
+		game := RandomZeroSum(rng, 3, 4)
+		strategies := averageStrategies(game, sampling.NewExternalSampler(), 50000)
+		if e := exploitability(game, strategies); e > 0.02 {
+			t.Errorf("game %d: expected MCCFR to converge to an equilibrium, exploitability %v", i, e)
+		}
+	}
+}
+
+func TestRandomBimatrix(t *testing.T) {
+	game := RandomBimatrix(rand.New(rand.NewSource(7)), 3, 2)
+	zeroSum := true
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 2; j++ {
+			a, b := game.Payoff([]int{i, j}, 0), game.Payoff([]int{i, j}, 1)
+			if a < -1 || a >= 1 || b < -1 || b >= 1 {
+				t.Errorf("payoffs (%v, %v) out of range", a, b)
+			}
+			if a+b != 0 {
+				zeroSum = false
+			}
+		}
+	}
+
+	if zeroSum {
+		t.Error("expected a general-sum game")
+	}
+}
@@ -2,6 +2,7 @@ package cfr
 
 import (
 	"bytes"
+	"encoding"
 	"encoding/gob"
 	"expvar"
 	"fmt"
@@ -17,62 +18,168 @@ func init() {
 	gob.Register(&PolicyTable{})
 }
 
+// tablePolicy is a NodePolicy stored in a policy table.
+type tablePolicy interface {
+	NodePolicy
+	NumActions() int
+	Renormalize()
+	SetStrategySum(sum []float32)
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// policyStore is the map of InfoSet Key -> policy of a policy table.
+type policyStore interface {
+	get(key string) (tablePolicy, bool)
+	put(key string, p tablePolicy)
+	forEach(f func(key string, p tablePolicy))
+	len() int
+	// reset clears the map, and preallocates it for n policies.
+	reset(n int)
+	// newPolicy returns a new policy with the given number of actions.
+	newPolicy(nActions int) tablePolicy
+}
+
+// policyTable implements the parts of PolicyTable and PolicyTable64 that
+// do not depend on how their policies store regrets and strategy sums.
+type policyTable struct {
+	params DiscountParams
+	iter   int
+
+	store         policyStore
+	mayNeedUpdate map[tablePolicy]struct{}
+
+	renormalizeEvery int
+}
+
+func newPolicyTable(params DiscountParams, store policyStore) policyTable {
+	return policyTable{
+		params:        params,
+		iter:          1,
+		store:         store,
+		mayNeedUpdate: make(map[tablePolicy]struct{}),
+	}
+}
+
 // PolicyTable implements traditional (tabular) CFR by storing accumulated
 // regrets and strategy sums for each InfoSet, which is looked up by its Key().
 type PolicyTable struct {
-	params DiscountParams
-	iter   int
+	policyTable
 
 	// Map of InfoSet Key -> the policy for that infoset.
 	PoliciesByKey map[string]*policy.Policy
-	mayNeedUpdate map[*policy.Policy]struct{}
 }
 
 // NewPolicyTable creates a new PolicyTable with the given DiscountParams.
 func NewPolicyTable(params DiscountParams) *PolicyTable {
-	return &PolicyTable{
-		params:        params,
-		iter:          1,
-		PoliciesByKey: make(map[string]*policy.Policy),
-		mayNeedUpdate: make(map[*policy.Policy]struct{}),
+	pt := &PolicyTable{PoliciesByKey: make(map[string]*policy.Policy)}
+	pt.policyTable = newPolicyTable(params, (*policyMap)(pt))
+	return pt
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (pt *PolicyTable) UnmarshalBinary(buf []byte) error {
+	// The table may have been allocated by the decoder.
+	pt.policyTable.store = (*policyMap)(pt)
+	return pt.policyTable.UnmarshalBinary(buf)
+}
+
+// policyMap implements policyStore for a PolicyTable.
+type policyMap PolicyTable
+
+func (m *policyMap) get(key string) (tablePolicy, bool) {
+	p, ok := m.PoliciesByKey[key]
+	return p, ok
+}
+
+func (m *policyMap) put(key string, p tablePolicy) {
+	m.PoliciesByKey[key] = p.(*policy.Policy)
+}
+
+func (m *policyMap) forEach(f func(key string, p tablePolicy)) {
+	for key, p := range m.PoliciesByKey {
+		f(key, p)
 	}
 }
 
+func (m *policyMap) len() int {
+	return len(m.PoliciesByKey)
+}
+
+func (m *policyMap) reset(n int) {
+	m.PoliciesByKey = make(map[string]*policy.Policy, n)
+}
+
+func (m *policyMap) newPolicy(nActions int) tablePolicy {
+	return policy.New(nActions)
+}
+
+// GetPolicyTable returns the map of InfoSet Key -> the policy for that infoset.
+func (pt *PolicyTable) GetPolicyTable() map[string]*policy.Policy {
+	return pt.PoliciesByKey
+}
+
 // Update performs regret matching for all nodes within this strategy profile that have
 // been touched since the lapt call to Update().
-func (pt *PolicyTable) Update() {
+func (pt *policyTable) Update() {
 	discountPos, discountNeg, discountSum := pt.params.GetDiscountFactors(pt.iter)
 	for p := range pt.mayNeedUpdate {
 		p.NextStrategy(discountPos, discountNeg, discountSum)
 		delete(pt.mayNeedUpdate, p)
 	}
 
+	if pt.renormalizeEvery > 0 && pt.iter%pt.renormalizeEvery == 0 {
+		pt.Renormalize()
+	}
+
 	pt.iter++
 }
 
-func (pt *PolicyTable) SetIter(val int) {
+// SetRenormalization makes Update renormalize the strategy sums of all
+// policies every n iterations, to prevent them from overflowing in long runs.
+// If n is not positive (the default), strategy sums are never renormalized.
+//
+// Renormalization does not change the average strategies. Each policy
+// reports the factor by which its strategy sums were rescaled with
+// GetStrategyWeightScale, which e.g. sampling.AverageStrategySampler
+// takes into account.
+func (pt *policyTable) SetRenormalization(n int) {
+	pt.renormalizeEvery = n
+}
+
+// Renormalize rescales the strategy sums of all policies to sum to 1,
+// without changing their average strategies.
+func (pt *policyTable) Renormalize() {
+	pt.store.forEach(func(key string, p tablePolicy) {
+		p.Renormalize()
+	})
+}
+
+func (pt *policyTable) SetIter(val int) {
 	pt.iter = val
-} 
+}
 
-func (pt *PolicyTable) Iter() int {
+func (pt *policyTable) Iter() int {
 	return pt.iter
 }
 
-func (pt *PolicyTable) Close() error {
+func (pt *policyTable) Close() error {
 	return nil
 }
 
-func (pt *PolicyTable) GetPolicyTable() map[string]*policy.Policy {
-	return pt.PoliciesByKey
+// insert adds a new policy with the given number of actions.
+func (pt *policyTable) insert(key string, nActions int) tablePolicy {
+	np := pt.store.newPolicy(nActions)
+	pt.store.put(key, np)
+	numInfosets.Set(int64(pt.store.len()))
+	return np
 }
 
-func (pt *PolicyTable) GetPolicy(node GameTreeNode) NodePolicy {
+func (pt *policyTable) GetPolicy(node GameTreeNode) NodePolicy {
 	key := string(node.InfoSetKey(node.Player()))
-	np, ok := pt.PoliciesByKey[string(key)]
+	np, ok := pt.store.get(key)
 	if !ok {
-		np = policy.New(node.NumChildren())
-		pt.PoliciesByKey[string(key)] = np
-		numInfosets.Set(int64(len(pt.PoliciesByKey)))
+		np = pt.insert(key, node.NumChildren())
 	} else if np.NumActions() != node.NumChildren() {
 		panic(fmt.Errorf("strategy has n_actions=%v but node has n_children=%v: %v",
 			np.NumActions(), node.NumChildren(), node))
@@ -82,38 +189,49 @@ func (pt *PolicyTable) GetPolicy(node GameTreeNode) NodePolicy {
 	return np
 }
 
-func (pt *PolicyTable) GetPolicyByKey(key string) (NodePolicy, bool) {
-	np, ok := pt.PoliciesByKey[key]
+// GetPolicyByKey returns the NodePolicy for the InfoSet with the given key,
+// and whether it exists.
+func (pt *policyTable) GetPolicyByKey(key string) (NodePolicy, bool) {
+	np, ok := pt.store.get(key)
 	if !ok {
-		np = policy.New(4)
-		pt.PoliciesByKey[key] = np
-		numInfosets.Set(int64(len(pt.PoliciesByKey)))
+		return nil, false
 	}
+
 	return np, true
 }
 
-func (pt *PolicyTable) SetStrategy(key string, strat []float32) {
-	np, ok := pt.PoliciesByKey[key]
+func (pt *policyTable) SetStrategy(key string, strat []float32) {
+	np, ok := pt.store.get(key)
 	if !ok {
-		np = policy.New(len(strat))
-		pt.PoliciesByKey[string(key)] = np
-		numInfosets.Set(int64(len(pt.PoliciesByKey)))
+		np = pt.insert(key, len(strat))
 	} else if np.NumActions() != len(strat) {
 		panic(fmt.Errorf("strategy has n_actions=%v but strategy's size is=%v",
 			np.NumActions(), len(strat)))
 	}
 	np.SetStrategy(strat)
-	pt.PoliciesByKey[string(key)] = np
 }
 
-func (pt *PolicyTable) Iterate(iterator func(key string, strat []float32)) {
-	for key, p := range pt.PoliciesByKey {
-		iterator(key, p.GetStrategy())
+// SetStrategySum sets the strategy sums, and therefore the average
+// strategy, of the InfoSet with the given key.
+func (pt *policyTable) SetStrategySum(key string, sum []float32) {
+	np, ok := pt.store.get(key)
+	if !ok {
+		np = pt.insert(key, len(sum))
+	} else if np.NumActions() != len(sum) {
+		panic(fmt.Errorf("strategy has n_actions=%v but strategy sum's size is=%v",
+			np.NumActions(), len(sum)))
 	}
+	np.SetStrategySum(sum)
+}
+
+func (pt *policyTable) Iterate(iterator func(key string, strat []float32)) {
+	pt.store.forEach(func(key string, p tablePolicy) {
+		iterator(key, p.GetStrategy())
+	})
 }
 
 // UnmarshalBinary implements encoding.BinaryUnmarshaler.
-func (pt *PolicyTable) UnmarshalBinary(buf []byte) error {
+func (pt *policyTable) UnmarshalBinary(buf []byte) error {
 	r := bytes.NewReader(buf)
 	dec := gob.NewDecoder(r)
 	if err := dec.Decode(&pt.params); err != nil {
@@ -129,27 +247,27 @@ func (pt *PolicyTable) UnmarshalBinary(buf []byte) error {
 		return err
 	}
 
-	pt.PoliciesByKey = make(map[string]*policy.Policy, nStrategies)
+	pt.store.reset(nStrategies)
 	for i := 0; i < nStrategies; i++ {
 		var key string
 		if err := dec.Decode(&key); err != nil {
 			return err
 		}
 
-		var p policy.Policy
-		if err := dec.Decode(&p); err != nil {
+		p := pt.store.newPolicy(0)
+		if err := dec.Decode(p); err != nil {
 			return err
 		}
 
-		pt.PoliciesByKey[key] = &p
+		pt.store.put(key, p)
 	}
 
-	pt.mayNeedUpdate = make(map[*policy.Policy]struct{})
+	pt.mayNeedUpdate = make(map[tablePolicy]struct{})
 	return nil
 }
 
 // MarshalBinary implements encoding.BinaryMarshaler.
-func (pt *PolicyTable) MarshalBinary() ([]byte, error) {
+func (pt *policyTable) MarshalBinary() ([]byte, error) {
 	var buf bytes.Buffer
 	enc := gob.NewEncoder(&buf)
 	if err := enc.Encode(pt.params); err != nil {
@@ -160,18 +278,23 @@ func (pt *PolicyTable) MarshalBinary() ([]byte, error) {
 		return nil, err
 	}
 
-	if err := enc.Encode(len(pt.PoliciesByKey)); err != nil {
+	if err := enc.Encode(pt.store.len()); err != nil {
 		return nil, err
 	}
 
-	for key, p := range pt.PoliciesByKey {
-		if err := enc.Encode(key); err != nil {
-			return nil, err
+	var err error
+	pt.store.forEach(func(key string, p tablePolicy) {
+		if err == nil {
+			err = enc.Encode(key)
 		}
 
-		if err := enc.Encode(p); err != nil {
-			return nil, err
+		if err == nil {
+			err = enc.Encode(p)
 		}
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
 	return buf.Bytes(), nil
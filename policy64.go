@@ -0,0 +1,65 @@
+package cfr
+
+import (
+	"encoding/gob"
+
+	"github.com/tam0705/go-cfr/internal/policy"
+)
+
+func init() {
+	gob.Register(&PolicyTable64{})
+}
+
+// PolicyTable64 is like PolicyTable, but accumulates regrets and strategy
+// sums in float64. It uses twice as much memory, but average strategies keep
+// improving over runs long enough for float32 sums to stop changing.
+type PolicyTable64 struct {
+	policyTable
+
+	// Map of InfoSet Key -> the policy for that infoset.
+	PoliciesByKey map[string]*policy.Policy64
+}
+
+// NewPolicyTable64 creates a new PolicyTable64 with the given DiscountParams.
+func NewPolicyTable64(params DiscountParams) *PolicyTable64 {
+	pt := &PolicyTable64{PoliciesByKey: make(map[string]*policy.Policy64)}
+	pt.policyTable = newPolicyTable(params, (*policyMap64)(pt))
+	return pt
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (pt *PolicyTable64) UnmarshalBinary(buf []byte) error {
+	// The table may have been allocated by the decoder.
+	pt.policyTable.store = (*policyMap64)(pt)
+	return pt.policyTable.UnmarshalBinary(buf)
+}
+
+// policyMap64 implements policyStore for a PolicyTable64.
+type policyMap64 PolicyTable64
+
+func (m *policyMap64) get(key string) (tablePolicy, bool) {
+	p, ok := m.PoliciesByKey[key]
+	return p, ok
+}
+
+func (m *policyMap64) put(key string, p tablePolicy) {
+	m.PoliciesByKey[key] = p.(*policy.Policy64)
+}
+
+func (m *policyMap64) forEach(f func(key string, p tablePolicy)) {
+	for key, p := range m.PoliciesByKey {
+		f(key, p)
+	}
+}
+
+func (m *policyMap64) len() int {
+	return len(m.PoliciesByKey)
+}
+
+func (m *policyMap64) reset(n int) {
+	m.PoliciesByKey = make(map[string]*policy.Policy64, n)
+}
+
+func (m *policyMap64) newPolicy(nActions int) tablePolicy {
+	return policy.New64(nActions)
+}
@@ -0,0 +1,124 @@
+package cfr_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// infoSetNode is a player node with the given InfoSet key.
+type infoSetNode struct {
+	cfr.GameTreeNode
+	key string
+	n   int
+}
+
+func (n infoSetNode) Player() int                  { return 0 }
+func (n infoSetNode) NumChildren() int             { return n.n }
+func (n infoSetNode) InfoSetKey(player int) []byte { return []byte(n.key) }
+
+type renormalizingProfile interface {
+	cfr.StrategyProfile
+	SetRenormalization(n int)
+}
+
+func TestSetRenormalization(t *testing.T) {
+	for name, pt := range map[string]renormalizingProfile{
+		"PolicyTable":   cfr.NewPolicyTable(cfr.DiscountParams{}),
+		"PolicyTable64": cfr.NewPolicyTable64(cfr.DiscountParams{}),
+	} {
+		pt.SetRenormalization(10)
+		nodes := []infoSetNode{{key: "a", n: 2}, {key: "b", n: 3}}
+		for i := 0; i < 10000; i++ {
+			for _, node := range nodes {
+				p := pt.GetPolicy(node)
+				regrets := make([]float32, node.n)
+				regrets[0] = 1.0
+				p.AddRegret(1.0, nil, regrets)
+				// Large enough to overflow float32 sums without renormalization.
+				p.AddStrategyWeight(1e36)
+			}
+
+			pt.Update()
+		}
+
+		for _, node := range nodes {
+			avg := pt.GetPolicy(node).GetAverageStrategy()
+			if math.IsNaN(float64(avg[0])) || avg[0] < 0.99 {
+				t.Errorf("%s: expected %s to converge to the first action, got %v", name, node.key, avg)
+			}
+		}
+	}
+}
+
+func TestPolicyTable64Marshal(t *testing.T) {
+	pt := cfr.NewPolicyTable64(cfr.DiscountParams{LinearWeighting: true})
+	node := infoSetNode{key: "a", n: 2}
+	p := pt.GetPolicy(node)
+	p.AddRegret(1.0, nil, []float32{1.0, 0.0})
+	p.AddStrategyWeight(1.0)
+	pt.Update()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(pt); err != nil {
+		t.Fatal(err)
+	}
+
+	var loaded *cfr.PolicyTable64
+	if err := gob.NewDecoder(&buf).Decode(&loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Iter() != pt.Iter() {
+		t.Errorf("expected iteration %d, got %d", pt.Iter(), loaded.Iter())
+	}
+
+	got, _ := loaded.GetPolicyByKey("a")
+	if got == nil || got.GetStrategy()[0] != 1.0 {
+		t.Errorf("expected loaded strategy [1 0], got %v", got)
+	}
+}
+
+func TestGetPolicyByKey(t *testing.T) {
+	for name, pt := range map[string]renormalizingProfile{
+		"PolicyTable":   cfr.NewPolicyTable(cfr.DiscountParams{}),
+		"PolicyTable64": cfr.NewPolicyTable64(cfr.DiscountParams{}),
+	} {
+		if p, ok := pt.GetPolicyByKey("a"); ok || p != nil {
+			t.Errorf("%s: expected no policy for an unseen infoset, got %v", name, p)
+		}
+
+		pt.GetPolicy(infoSetNode{key: "a", n: 3})
+		if p, ok := pt.GetPolicyByKey("a"); !ok || len(p.GetStrategy()) != 3 {
+			t.Errorf("%s: expected policy with 3 actions, got %v", name, p)
+		}
+	}
+}
+
+func TestSetStrategySum(t *testing.T) {
+	for name, pt := range map[string]interface {
+		cfr.StrategyProfile
+		SetStrategySum(key string, sum []float32)
+	}{
+		"PolicyTable":   cfr.NewPolicyTable(cfr.DiscountParams{}),
+		"PolicyTable64": cfr.NewPolicyTable64(cfr.DiscountParams{}),
+	} {
+		pt.SetStrategySum("a", []float32{1, 3})
+		p, ok := pt.GetPolicyByKey("a")
+		if !ok {
+			t.Fatalf("%s: expected a policy for infoset a", name)
+		}
+
+		if avg := p.GetAverageStrategy(); avg[0] != 0.25 || avg[1] != 0.75 {
+			t.Errorf("%s: expected average strategy [0.25 0.75], got %v", name, avg)
+		}
+
+		// The current strategy is unchanged.
+		if strat := p.GetStrategy(); strat[0] != 0.5 || strat[1] != 0.5 {
+			t.Errorf("%s: expected uniform strategy, got %v", name, strat)
+		}
+	}
+}
@@ -4,8 +4,6 @@ import (
 	"math/rand"
 
 	"github.com/tam0705/go-cfr"
-	"github.com/tam0705/go-cfr/internal/f32"
-	"github.com/tam0705/go-cfr/internal/policy"
 )
 
 type AverageStrategyParams struct {
@@ -14,30 +12,82 @@ type AverageStrategyParams struct {
 	Beta    float32 // b >= 0
 }
 
+// AverageStrategySchedule determines the AverageStrategyParams as a
+// function of the current training iteration.
+type AverageStrategySchedule struct {
+	Epsilon Schedule
+	Tau     Schedule
+	Beta    Schedule
+}
+
+// Params returns the AverageStrategyParams for the given iteration.
+func (s AverageStrategySchedule) Params(iter int) AverageStrategyParams {
+	return AverageStrategyParams{
+		Epsilon: s.Epsilon.Value(iter),
+		Tau:     s.Tau.Value(iter),
+		Beta:    s.Beta.Value(iter),
+	}
+}
+
 // AverageStrategySampler implements cfr.Sampler by sampling some player actions
 // according to the current average strategy strategy.
 type AverageStrategySampler struct {
-	params AverageStrategyParams
-	rng    *rand.Rand
-	p      []float32
+	sp       cfr.StrategyProfile
+	schedule AverageStrategySchedule
+	rng      *rand.Rand
+	p        []float32
 }
 
 func NewAverageStrategySampler(params AverageStrategyParams) *AverageStrategySampler {
+	return NewScheduledAverageStrategySampler(nil, AverageStrategySchedule{
+		Epsilon: ConstantSchedule(params.Epsilon),
+		Tau:     ConstantSchedule(params.Tau),
+		Beta:    ConstantSchedule(params.Beta),
+	})
+}
+
+// NewScheduledAverageStrategySampler returns an AverageStrategySampler whose
+// parameters follow the given schedule. As for NewScheduledOutcomeSampler,
+// sp may be nil if the sampler is only used by MCCFR, or the schedule is
+// constant.
+func NewScheduledAverageStrategySampler(sp cfr.StrategyProfile, schedule AverageStrategySchedule) *AverageStrategySampler {
 	return &AverageStrategySampler{
-		params: params,
-		rng:    rand.New(rand.NewSource(rand.Int63())),
+		sp:       sp,
+		schedule: schedule,
+		rng:      rand.New(rand.NewSource(rand.Int63())),
 	}
 }
 
 func (as *AverageStrategySampler) Sample(node cfr.GameTreeNode, pol cfr.NodePolicy) []float32 {
+	return as.SampleWithContext(node, pol, cfr.SampleContext{})
+}
+
+// SampleWithContext implements cfr.ContextSampler, evaluating the
+// schedule at sc.Iter.
+func (as *AverageStrategySampler) SampleWithContext(node cfr.GameTreeNode, pol cfr.NodePolicy, sc cfr.SampleContext) []float32 {
 	nChildren := node.NumChildren()
 	as.p = extend(as.p, nChildren)
 
+	mask := node.LegalActions()
+	schedule := as.schedule
+	params := schedule.Params(scheduleIter(sc, as.sp, schedule.Epsilon, schedule.Tau, schedule.Beta))
 	x := as.rng.Float32()
-	s := pol.(*policy.Policy).GetStrategySum()
-	sSum := f32.Sum(s)
+	s := pol.GetStrategySum()
+	scale := pol.GetStrategyWeightScale()
+	var sSum float64
+	for i, si := range s {
+		if cfr.IsLegal(mask, i) {
+			sSum += float64(si)
+		}
+	}
+
 	for i := range as.p {
-		rho := computeRho(s[i], sSum, as.params)
+		if !cfr.IsLegal(mask, i) {
+			as.p[i] = 0
+			continue
+		}
+
+		rho := computeRho(s[i], sSum, scale, params)
 		if x < rho {
 			as.p[i] = minF32(rho, 1.0)
 		} else {
@@ -56,9 +106,12 @@ func minF32(x, y float32) float32 {
 	return y
 }
 
-func computeRho(s, sSum float32, params AverageStrategyParams) float32 {
-	rho := params.Beta + params.Tau*s
-	rho /= params.Beta + sSum
+// computeRho returns the probability of sampling an action with strategy
+// sum s, out of sSum for all actions. The sums are scaled by scale relative
+// to the weights they were accumulated from, so the beta parameter is too.
+func computeRho(s float32, sSum, scale float64, params AverageStrategyParams) float32 {
+	beta := float64(params.Beta) * scale
+	rho := float32((beta + float64(params.Tau)*float64(s)) / (beta + sSum))
 	if rho < params.Epsilon {
 		return params.Epsilon
 	}
@@ -0,0 +1,51 @@
+package sampling
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// playerNode is a minimal cfr.GameTreeNode with n legal actions.
+type playerNode struct {
+	cfr.GameTreeNode
+	n int
+}
+
+func (n *playerNode) NumChildren() int     { return n.n }
+func (n *playerNode) LegalActions() []bool { return nil }
+
+// strategySumPolicy is a cfr.NodePolicy backed only by a strategy sum,
+// standing in for an alternative storage backend.
+type strategySumPolicy struct {
+	cfr.NodePolicy
+	strategySum []float32
+	scale       float64
+}
+
+func (p *strategySumPolicy) GetStrategySum() []float32       { return p.strategySum }
+func (p *strategySumPolicy) GetStrategyWeightScale() float64 { return p.scale }
+
+func TestAverageStrategySamplerWithAnyPolicy(t *testing.T) {
+	node := &playerNode{n: 3}
+	pol := &strategySumPolicy{strategySum: []float32{0, 0, 100}, scale: 1}
+	s := NewAverageStrategySampler(AverageStrategyParams{Epsilon: 0.05, Tau: 1.0, Beta: 0})
+
+	for i := 0; i < 100; i++ {
+		p := s.Sample(node, pol)
+		if p[2] != 1.0 {
+			t.Fatalf("expected the only action with positive weight to always be sampled, got %v", p)
+		}
+	}
+}
+
+func TestComputeRhoRenormalized(t *testing.T) {
+	params := AverageStrategyParams{Epsilon: 0.05, Tau: 2.0, Beta: 10}
+	// The same strategy sums, before and after renormalizing them to sum to 1.
+	want := computeRho(30, 40, 1.0, params)
+	got := computeRho(0.75, 1.0, 1.0/40, params)
+	if math.Abs(float64(got-want)) > 1e-6 {
+		t.Errorf("renormalization changed the sampling probability from %v to %v", want, got)
+	}
+}
@@ -0,0 +1,159 @@
+package sampling
+
+import (
+	"math/rand"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// OnPolicyChanceSampler implements cfr.ChanceSampler by sampling one child
+// according to the chance node's probability distribution.
+type OnPolicyChanceSampler struct {
+	rng *rand.Rand
+	w   []float32
+}
+
+func NewOnPolicyChanceSampler() *OnPolicyChanceSampler {
+	return &OnPolicyChanceSampler{
+		rng: rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+func (cs *OnPolicyChanceSampler) SampleChance(node cfr.GameTreeNode) []float32 {
+	nChildren := node.NumChildren()
+	cs.w = extend(cs.w, nChildren)
+	for i := range cs.w {
+		cs.w[i] = 0 // memclr
+	}
+
+	// The sampling probability equals the chance probability, so they cancel.
+	selected := sampleChanceIndex(node, cs.rng.Float64())
+	cs.w[selected] = 1.0
+	return cs.w
+}
+
+// ChanceProposal returns the (unnormalized) probability with which the ith
+// child of the given chance node should be sampled.
+type ChanceProposal func(node cfr.GameTreeNode, i int) float64
+
+// ImportanceChanceSampler implements cfr.ChanceSampler by sampling one child
+// according to a proposal distribution, and weighting it by the ratio of
+// its true probability to its proposal probability.
+//
+// The proposal must be positive for every child with positive probability.
+// If it is zero for all children, one is sampled on-policy instead.
+type ImportanceChanceSampler struct {
+	proposal ChanceProposal
+	rng      *rand.Rand
+	q        []float64
+	w        []float32
+}
+
+func NewImportanceChanceSampler(proposal ChanceProposal) *ImportanceChanceSampler {
+	return &ImportanceChanceSampler{
+		proposal: proposal,
+		rng:      rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+func (cs *ImportanceChanceSampler) SampleChance(node cfr.GameTreeNode) []float32 {
+	nChildren := node.NumChildren()
+	cs.w = extend(cs.w, nChildren)
+	if cap(cs.q) < nChildren {
+		cs.q = make([]float64, nChildren)
+	}
+	cs.q = cs.q[:nChildren]
+
+	var total float64
+	for i := range cs.q {
+		cs.q[i] = cs.proposal(node, i)
+		total += cs.q[i]
+	}
+
+	for i := range cs.w {
+		cs.w[i] = 0 // memclr
+	}
+
+	if !(total > 0) {
+		// The proposal is not a distribution over the children,
+		// so fall back to sampling them on-policy.
+		cs.w[sampleChanceIndex(node, cs.rng.Float64())] = 1.0
+		return cs.w
+	}
+
+	x := cs.rng.Float64() * total
+	selected := nChildren - 1
+	var cumProb float64
+	for i, q := range cs.q {
+		cumProb += q
+		if cumProb > x {
+			selected = i
+			break
+		}
+	}
+
+	q := cs.q[selected] / total
+	cs.w[selected] = float32(node.GetChildProbability(selected) / q)
+	return cs.w
+}
+
+// StratifiedChanceSampler implements cfr.ChanceSampler by drawing k samples,
+// one from each of k equal-probability strata of the chance node's
+// cumulative distribution. Each sampled child is weighted by the fraction
+// of samples that selected it.
+type StratifiedChanceSampler struct {
+	k   int
+	rng *rand.Rand
+	w   []float32
+}
+
+func NewStratifiedChanceSampler(k int) *StratifiedChanceSampler {
+	return &StratifiedChanceSampler{
+		k:   k,
+		rng: rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+func (cs *StratifiedChanceSampler) SampleChance(node cfr.GameTreeNode) []float32 {
+	nChildren := node.NumChildren()
+	cs.w = extend(cs.w, nChildren)
+	for i := range cs.w {
+		cs.w[i] = 0 // memclr
+	}
+
+	// The strata are visited in increasing order, so the CDF can be
+	// scanned once for all k samples.
+	var cumProb float64
+	i := 0
+	for j := 0; j < cs.k; j++ {
+		x := (float64(j) + cs.rng.Float64()) / float64(cs.k)
+		for i < nChildren-1 && cumProb+node.GetChildProbability(i) <= x {
+			cumProb += node.GetChildProbability(i)
+			i++
+		}
+
+		cs.w[i] += 1.0 / float32(cs.k)
+	}
+
+	return cs.w
+}
+
+// EnumeratingChanceSampler implements cfr.ChanceSampler by traversing every
+// child of the chance node, weighted by its probability.
+type EnumeratingChanceSampler struct {
+	w []float32
+}
+
+func NewEnumeratingChanceSampler() *EnumeratingChanceSampler {
+	return &EnumeratingChanceSampler{}
+}
+
+func (cs *EnumeratingChanceSampler) SampleChance(node cfr.GameTreeNode) []float32 {
+	nChildren := node.NumChildren()
+	cs.w = extend(cs.w, nChildren)
+	for i := range cs.w {
+		cs.w[i] = float32(node.GetChildProbability(i))
+	}
+
+	return cs.w
+}
@@ -0,0 +1,49 @@
+package sampling
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// chanceNode is a minimal cfr.GameTreeNode with a fixed distribution over children.
+type chanceNode struct {
+	cfr.GameTreeNode
+	p []float64
+}
+
+func (n *chanceNode) NumChildren() int                  { return len(n.p) }
+func (n *chanceNode) GetChildProbability(i int) float64 { return n.p[i] }
+
+func TestChanceSamplersAreUnbiased(t *testing.T) {
+	node := &chanceNode{p: []float64{0.001, 0.05, 0.25, 0.699}}
+	// Oversample the rare children.
+	proposal := func(node cfr.GameTreeNode, i int) float64 { return 1.0 }
+
+	samplers := map[string]cfr.ChanceSampler{
+		"on-policy":  NewOnPolicyChanceSampler(),
+		"importance": NewImportanceChanceSampler(proposal),
+		// A proposal with no weight falls back to on-policy sampling.
+		"importance (zero)": NewImportanceChanceSampler(func(cfr.GameTreeNode, int) float64 { return 0 }),
+		"stratified":        NewStratifiedChanceSampler(3),
+		"enumerating":       NewEnumeratingChanceSampler(),
+	}
+
+	const n = 200000
+	for name, cs := range samplers {
+		total := make([]float64, len(node.p))
+		for i := 0; i < n; i++ {
+			for j, w := range cs.SampleChance(node) {
+				total[j] += float64(w)
+			}
+		}
+
+		for j, p := range node.p {
+			mean := total[j] / n
+			if math.Abs(mean-p) > 0.01 {
+				t.Errorf("%s: child %d has mean weight %.4f, expected %.4f", name, j, mean, p)
+			}
+		}
+	}
+}
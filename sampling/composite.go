@@ -0,0 +1,91 @@
+package sampling
+
+import (
+	"fmt"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// Rule chooses which of a CompositeSampler's samplers should sample the
+// given node, by returning its index.
+type Rule func(node cfr.GameTreeNode, sc cfr.SampleContext) int
+
+// CompositeSampler implements cfr.ContextSampler by routing each call to
+// one of several samplers, chosen by a Rule.
+type CompositeSampler struct {
+	rule     Rule
+	samplers []cfr.ContextSampler
+}
+
+// NewCompositeSampler returns a CompositeSampler that samples each node with
+// samplers[rule(node, sc)].
+func NewCompositeSampler(rule Rule, samplers ...cfr.Sampler) *CompositeSampler {
+	cs := &CompositeSampler{
+		rule:     rule,
+		samplers: make([]cfr.ContextSampler, len(samplers)),
+	}
+
+	for i, s := range samplers {
+		cs.samplers[i] = cfr.IgnoreContext(s)
+	}
+
+	return cs
+}
+
+// Sample implements cfr.Sampler. Rules that depend on the SampleContext
+// will see its zero value.
+func (cs *CompositeSampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) []float32 {
+	return cs.SampleWithContext(node, policy, cfr.SampleContext{})
+}
+
+func (cs *CompositeSampler) SampleWithContext(node cfr.GameTreeNode, policy cfr.NodePolicy, sc cfr.SampleContext) []float32 {
+	i := cs.rule(node, sc)
+	if i < 0 || i >= len(cs.samplers) {
+		panic(fmt.Errorf("rule selected sampler %d but there are only %d samplers! node: %v",
+			i, len(cs.samplers), node))
+	}
+
+	return cs.samplers[i].SampleWithContext(node, policy, sc)
+}
+
+// ByDepth returns a Rule that selects sampler i for nodes with depth
+// less than depths[i], and sampler len(depths) for all deeper nodes.
+// The depths must be increasing.
+func ByDepth(depths ...int) Rule {
+	return func(node cfr.GameTreeNode, sc cfr.SampleContext) int {
+		for i, d := range depths {
+			if sc.Depth < d {
+				return i
+			}
+		}
+
+		return len(depths)
+	}
+}
+
+// ByPlayer returns a Rule that selects sampler i for nodes where player i acts.
+func ByPlayer() Rule {
+	return func(node cfr.GameTreeNode, sc cfr.SampleContext) int {
+		return node.Player()
+	}
+}
+
+// ByInfoSetKey returns a Rule that selects the sampler returned by f
+// for the acting player's InfoSet key.
+func ByInfoSetKey(f func(key []byte) int) Rule {
+	return func(node cfr.GameTreeNode, sc cfr.SampleContext) int {
+		return f(node.InfoSetKey(node.Player()))
+	}
+}
+
+// ByPredicate returns a Rule that selects sampler 0 for nodes whose InfoSet
+// key satisfies pred, and sampler 1 otherwise.
+func ByPredicate(pred func(key []byte) bool) Rule {
+	return ByInfoSetKey(func(key []byte) int {
+		if pred(key) {
+			return 0
+		}
+
+		return 1
+	})
+}
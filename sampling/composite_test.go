@@ -0,0 +1,50 @@
+package sampling
+
+import (
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// constSampler is a cfr.Sampler that always returns the same probabilities.
+type constSampler []float32
+
+func (s constSampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) []float32 {
+	return s
+}
+
+// keyedNode is a minimal cfr.GameTreeNode with a player and InfoSet key.
+type keyedNode struct {
+	cfr.GameTreeNode
+	player int
+	key    string
+}
+
+func (n *keyedNode) Player() int                  { return n.player }
+func (n *keyedNode) InfoSetKey(player int) []byte { return []byte(n.key) }
+
+func TestCompositeSampler(t *testing.T) {
+	a, b, c := constSampler{0}, constSampler{1}, constSampler{2}
+
+	testCases := []struct {
+		name     string
+		sampler  *CompositeSampler
+		node     *keyedNode
+		sc       cfr.SampleContext
+		expected float32
+	}{
+		{"shallow", NewCompositeSampler(ByDepth(2, 5), a, b, c), &keyedNode{}, cfr.SampleContext{Depth: 1}, 0},
+		{"middle", NewCompositeSampler(ByDepth(2, 5), a, b, c), &keyedNode{}, cfr.SampleContext{Depth: 2}, 1},
+		{"deep", NewCompositeSampler(ByDepth(2, 5), a, b, c), &keyedNode{}, cfr.SampleContext{Depth: 9}, 2},
+		{"player", NewCompositeSampler(ByPlayer(), a, b), &keyedNode{player: 1}, cfr.SampleContext{}, 1},
+		{"predicate", NewCompositeSampler(ByPredicate(func(key []byte) bool { return key[0] == 'x' }), a, b),
+			&keyedNode{key: "xy"}, cfr.SampleContext{}, 0},
+	}
+
+	for _, tc := range testCases {
+		p := tc.sampler.SampleWithContext(tc.node, nil, tc.sc)
+		if p[0] != tc.expected {
+			t.Errorf("%s: expected sampler %v, got %v", tc.name, tc.expected, p[0])
+		}
+	}
+}
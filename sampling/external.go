@@ -2,7 +2,7 @@ package sampling
 
 import "github.com/tam0705/go-cfr"
 
-// ExternalSampler implements cfr.Sampler by sampling all player actions.
+// ExternalSampler implements cfr.Sampler by sampling all legal player actions.
 type ExternalSampler struct {
 	p []float32
 }
@@ -13,9 +13,15 @@ func NewExternalSampler() *ExternalSampler {
 
 func (es *ExternalSampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) []float32 {
 	nChildren := node.NumChildren()
-	for len(es.p) < nChildren {
-		es.p = append(es.p, 1.0)
+	es.p = extend(es.p, nChildren)
+	mask := node.LegalActions()
+	for i := range es.p {
+		if cfr.IsLegal(mask, i) {
+			es.p[i] = 1.0
+		} else {
+			es.p[i] = 0
+		}
 	}
 
-	return es.p[:nChildren]
+	return es.p
 }
@@ -1,25 +1,59 @@
 package sampling
 
 import (
+	"math"
 	"math/rand"
 
 	"github.com/tam0705/go-cfr"
 	"github.com/tam0705/go-cfr/internal/f32"
 )
 
-// MultiOutcomeSampler implements cfr.Sampler by sampling at most k player actions
-// with probability according to the current strategy.
+// SamplingDesign is the procedure by which MultiOutcomeSampler
+// chooses k distinct actions.
+type SamplingDesign int
+
+const (
+	// ConditionalPoissonSampling chooses each set of k actions with
+	// probability proportional to the product of their probabilities in
+	// the strategy. Inclusion probabilities are computed exactly in
+	// O(n * k) time.
+	ConditionalPoissonSampling SamplingDesign = iota
+	// SystematicSampling selects k actions by systematic sampling with
+	// inclusion probabilities proportional to the strategy (capped at 1).
+	// Inclusion probabilities are known in closed form, in O(n) time.
+	SystematicSampling
+)
+
+// MultiOutcomeSampler implements cfr.Sampler by sampling at most k legal player
+// actions with probability according to the current strategy.
 type MultiOutcomeSampler struct {
-	k    int
-	eps  float32
-	rng  *rand.Rand
-	p    []float32
-	pool *floatSlicePool
+	k      int
+	sp     cfr.StrategyProfile
+	eps    Schedule
+	design SamplingDesign
+	rng    *rand.Rand
+	p      []float32
+	pool   *floatSlicePool
+
+	// Scratch space for conditional Poisson sampling: the elementary
+	// symmetric polynomials of each suffix of the weights, and the
+	// probability of each number of actions left to choose.
+	esp   []float64
+	reach []float64
 }
 
 func NewMultiOutcomeSampler(k int, explorationEps float32) *MultiOutcomeSampler {
+	return NewScheduledMultiOutcomeSampler(nil, k, ConstantSchedule(explorationEps))
+}
+
+// NewScheduledMultiOutcomeSampler returns a MultiOutcomeSampler whose
+// exploration epsilon follows the given schedule. As for
+// NewScheduledOutcomeSampler, sp may be nil if the sampler is only used
+// by MCCFR, or the schedule is constant.
+func NewScheduledMultiOutcomeSampler(sp cfr.StrategyProfile, k int, explorationEps Schedule) *MultiOutcomeSampler {
 	return &MultiOutcomeSampler{
 		k:    k,
+		sp:   sp,
 		eps:  explorationEps,
 		rng:  rand.New(rand.NewSource(rand.Int63())),
 		p:    make([]float32, k),
@@ -27,74 +61,218 @@ func NewMultiOutcomeSampler(k int, explorationEps float32) *MultiOutcomeSampler
 	}
 }
 
+// SetDesign sets the procedure used to choose the k sampled actions.
+// The default is ConditionalPoissonSampling.
+func (os *MultiOutcomeSampler) SetDesign(design SamplingDesign) {
+	os.design = design
+}
+
 func (os *MultiOutcomeSampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) []float32 {
+	return os.SampleWithContext(node, policy, cfr.SampleContext{})
+}
+
+// SampleWithContext implements cfr.ContextSampler, evaluating the
+// exploration schedule at sc.Iter.
+func (os *MultiOutcomeSampler) SampleWithContext(node cfr.GameTreeNode, policy cfr.NodePolicy, sc cfr.SampleContext) []float32 {
 	nChildren := node.NumChildren()
 	os.p = extend(os.p, nChildren)
-	if nChildren <= os.k {
-		for i := range os.p[:nChildren] {
-			os.p[i] = 1.0
+	mask := node.LegalActions()
+	nLegal := cfr.NumLegal(mask, nChildren)
+	if nLegal <= os.k {
+		for i := range os.p {
+			if cfr.IsLegal(mask, i) {
+				os.p[i] = 1.0
+			} else {
+				os.p[i] = 0
+			}
 		}
 
-		return os.p[:nChildren]
+		return os.p
 	}
 
 	// p is the result to return.
-	// q is the p-vector to sample from next.
-	// r is a p-vector of the k-sampling probabilities.
-	// We copy r[i] into p[i] for the k sampled actions.
+	// q is the p-vector to choose the k actions from.
+	// We copy the inclusion probabilities into p for the k sampled actions.
 	for i := range os.p {
 		os.p[i] = 0 // memclr
 	}
 
 	q := os.pool.alloc(nChildren)
-	copy(q, policy.GetStrategy())
-	f32.AddConst(os.eps/float32(nChildren), q)
+	copy(q, policy.GetLegalStrategy(mask))
+	eps := os.eps.Value(scheduleIter(sc, os.sp, os.eps))
+	f32.AddConst(eps/float32(nLegal), q)
+	cfr.ApplyMask(q, mask)
 	f32.ScalUnitary(1.0/f32.Sum(q), q) // Renormalize.
 
-	// Compute probability of choosing i if we draw k times.
-	qEff := os.chooseK(q)
-
-	for i := 0; i < os.k; i++ {
-		sampled := SampleOne(q, os.rng.Float32())
-		os.p[sampled] = qEff[sampled]
-
-		// Remove sampled action from being re-sampled.
-		qSample := q[sampled]
-		q[sampled] = 0
-		f32.ScalUnitary(1.0/(1.0-qSample), q)
+	if os.design == SystematicSampling {
+		os.sampleSystematic(q)
+	} else {
+		os.sampleConditionalPoisson(q)
 	}
 
-	os.pool.free(qEff)
 	os.pool.free(q)
 	return os.p
 }
 
+// sampleConditionalPoisson chooses k actions with probability proportional
+// to the product of their weights in q, and stores their inclusion
+// probabilities in p. If fewer than k actions have positive weight, all of
+// them are chosen.
+func (os *MultiOutcomeSampler) sampleConditionalPoisson(q []float32) {
+	pi := os.chooseK(q)
+
+	// Choose each action in turn with its probability of being among the
+	// r actions left to choose from those that remain.
+	k := nPositive(q, os.k)
+	r := k
+	for i, qi := range q {
+		if r == 0 {
+			break
+		}
+
+		suffix, next := os.esp[i*(k+1):], os.esp[(i+1)*(k+1):]
+		if os.rng.Float64()*suffix[r] < float64(qi)*next[r-1] {
+			os.p[i] = pi[i]
+			r--
+		}
+	}
+
+	os.pool.free(pi)
+}
+
+// nPositive returns the number of positive weights in q, up to k.
+func nPositive(q []float32, k int) int {
+	n := 0
+	for _, x := range q {
+		if x > 0 && n < k {
+			n++
+		}
+	}
+
+	return n
+}
+
+// chooseK returns the inclusion probability of each action when choosing
+// k actions by conditional Poisson sampling with weights p, or all actions
+// with positive weight if there are at most k.
+//
+// The probability of choosing a set S is prod_{i in S} p[i] / e_k(p), where
+// e_r is the rth elementary symmetric polynomial. The inclusion
+// probabilities follow from a dynamic program over the actions and the
+// number left to choose, which takes O(n * k) time.
 func (os *MultiOutcomeSampler) chooseK(p []float32) []float32 {
-	result := os.pool.alloc(len(p))
+	n := len(p)
+	k := nPositive(p, os.k)
+	result := os.pool.alloc(n)
+
+	// esp[i*(k+1) + r] is e_r(p[i:]).
+	os.esp = extend64(os.esp, (n+1)*(k+1))
+	for r := range os.esp[n*(k+1):] {
+		os.esp[n*(k+1)+r] = 0
+	}
+	os.esp[n*(k+1)] = 1.0
+	for i := n - 1; i >= 0; i-- {
+		suffix, next := os.esp[i*(k+1):(i+1)*(k+1)], os.esp[(i+1)*(k+1):]
+		suffix[0] = 1.0
+		for r := 1; r <= k; r++ {
+			suffix[r] = next[r] + float64(p[i])*next[r-1]
+		}
+	}
+
+	// reach[r] is the probability of having r actions left to choose.
+	os.reach = extend64(os.reach, k+1)
+	for r := range os.reach {
+		os.reach[r] = 0
+	}
+	os.reach[k] = 1.0
+	for i, pi := range p {
+		suffix, next := os.esp[i*(k+1):], os.esp[(i+1)*(k+1):]
+		var inclusion float64
+		// Ascending, so that probability moved to r-1 is not moved again.
+		for r := 1; r <= k; r++ {
+			if os.reach[r] == 0 || suffix[r] == 0 {
+				continue
+			}
+
+			chosen := os.reach[r] * float64(pi) * next[r-1] / suffix[r]
+			inclusion += chosen
+			os.reach[r] -= chosen
+			os.reach[r-1] += chosen
+		}
 
-	for j := range p {
-		result[j] = os.chooseKHelper(p, j, os.k)
+		result[i] = float32(inclusion)
 	}
 
 	return result
 }
 
-func (os *MultiOutcomeSampler) chooseKHelper(p []float32, j, k int) float32 {
-	if k == 1 {
-		return p[j]
+func extend64(v []float64, n int) []float64 {
+	if cap(v) < n {
+		return make([]float64, n)
 	}
 
-	var descendant float32
-	for i := range p {
-		if i != j && p[i] > 0 {
-			choseI := os.pool.alloc(len(p))
-			copy(choseI, p)
-			choseI[i] = 0
-			f32.ScalUnitary(1.0/(1-p[i]), choseI)
-			descendant += p[i] * os.chooseKHelper(choseI, j, k-1)
-			os.pool.free(choseI)
+	return v[:n]
+}
+
+// sampleSystematic chooses k actions by systematic sampling, with inclusion
+// probabilities proportional to q (capped at 1), and stores their inclusion
+// probabilities in p.
+func (os *MultiOutcomeSampler) sampleSystematic(q []float32) {
+	pi := os.pool.alloc(len(q))
+	inclusionProbabilities(q, os.k, pi)
+
+	// Lay the inclusion probabilities end to end on [0, k), and select
+	// each action whose interval contains one of u, u+1, ..., u+k-1.
+	u := os.rng.Float64()
+	var cumProb float64
+	for i, x := range pi {
+		lo := cumProb
+		cumProb += float64(x)
+		if math.Floor(cumProb-u) > math.Floor(lo-u) {
+			os.p[i] = x
 		}
 	}
 
-	return p[j] + descendant
+	os.pool.free(pi)
+}
+
+// inclusionProbabilities sets pi to the inclusion probabilities for
+// choosing k of the actions proportional to q: pi[i] = min(1, c*q[i]),
+// where c is such that the inclusion probabilities sum to k.
+func inclusionProbabilities(q []float32, k int, pi []float32) {
+	for i := range pi {
+		pi[i] = 0 // memclr
+	}
+
+	// Actions whose inclusion probability would exceed 1 are always
+	// included; repeat until none of the remainder exceed 1.
+	for {
+		nCapped := 0
+		var uncapped float32
+		for i, x := range q {
+			if pi[i] == 1.0 {
+				nCapped++
+			} else {
+				uncapped += x
+			}
+		}
+
+		c := float32(k-nCapped) / uncapped
+		done := true
+		for i, x := range q {
+			if pi[i] == 1.0 {
+				continue
+			}
+
+			pi[i] = c * x
+			if pi[i] >= 1.0 {
+				pi[i] = 1.0
+				done = false
+			}
+		}
+
+		if done {
+			return
+		}
+	}
 }
@@ -1,16 +1,201 @@
 package sampling
 
 import (
+	"math"
 	"testing"
+
+	"github.com/tam0705/go-cfr/internal/f32"
 )
 
+// bruteForceChooseK computes the probability of choosing j when choosing
+// k actions by conditional Poisson sampling with weights p, by enumerating
+// all sets of k actions.
+func bruteForceChooseK(p []float32, j, k int) float32 {
+	var total, withJ float64
+	var enumerate func(next, left int, prob float64, hasJ bool)
+	enumerate = func(next, left int, prob float64, hasJ bool) {
+		if left == 0 {
+			total += prob
+			if hasJ {
+				withJ += prob
+			}
+			return
+		}
+
+		for i := next; i <= len(p)-left; i++ {
+			enumerate(i+1, left-1, prob*float64(p[i]), hasJ || i == j)
+		}
+	}
+	enumerate(0, k, 1.0, false)
+
+	return float32(withJ / total)
+}
+
+func checkChooseK(t *testing.T, p0 []float32, k int) {
+	t.Helper()
+	s := NewMultiOutcomeSampler(k, 0)
+	pk := s.chooseK(p0)
+	if total := f32.Sum(pk); math.Abs(float64(total)-float64(k)) > 1e-4 {
+		t.Errorf("k=%d: inclusion probabilities sum to %v", k, total)
+	}
+
+	for j := range p0 {
+		expected := bruteForceChooseK(p0, j, k)
+		if math.Abs(float64(pk[j]-expected)) > 1e-5 {
+			t.Errorf("k=%d: action %d has inclusion probability %v, expected %v",
+				k, j, pk[j], expected)
+		}
+	}
+}
+
 func TestChooseK(t *testing.T) {
 	p0 := []float32{0.01, 0.1, 0.1, 0.79}
-	eps := float32(0.05)
-
 	for k := 1; k <= 4; k++ {
-		s := NewMultiOutcomeSampler(k, eps)
-		pk := s.chooseK(p0)
-		t.Logf("k=%d: %v", k, pk)
+		checkChooseK(t, p0, k)
+	}
+}
+
+func TestChooseKWide(t *testing.T) {
+	p0 := []float32{0.01, 0.02, 0.05, 0.07, 0.1, 0.15, 0.2, 0.4}
+	for k := 1; k <= 5; k++ {
+		checkChooseK(t, p0, k)
+	}
+
+	// A wide node, with C(30, 5) = 142506 sets of actions.
+	p0 = make([]float32, 30)
+	for i := range p0 {
+		p0[i] = float32(i+1) / 465
+	}
+	checkChooseK(t, p0, 5)
+
+	p0 = make([]float32, 200)
+	for i := range p0 {
+		p0[i] = 1.0 / 200
+	}
+	s := NewMultiOutcomeSampler(8, 0)
+	for j, x := range s.chooseK(p0) {
+		if math.Abs(float64(x)-8.0/200) > 1e-5 {
+			t.Errorf("action %d has inclusion probability %v, expected %v", j, x, 8.0/200)
+		}
+	}
+}
+
+func TestChooseKFewPositive(t *testing.T) {
+	// Only two actions can be chosen, so both always are.
+	p0 := []float32{0, 0.25, 0, 0.75, 0}
+	s := NewMultiOutcomeSampler(3, 0)
+	pk := s.chooseK(p0)
+	want := []float32{0, 1, 0, 1, 0}
+	for j := range want {
+		if math.Abs(float64(pk[j]-want[j])) > 1e-6 {
+			t.Errorf("expected inclusion probabilities %v, got %v", want, pk)
+			break
+		}
+	}
+
+	s.p = extend(s.p, len(p0))
+	s.sampleConditionalPoisson(p0)
+	if s.p[1] != 1 || s.p[3] != 1 {
+		t.Errorf("expected both positive actions to be sampled, got %v", s.p)
+	}
+}
+
+func TestConditionalPoissonSampling(t *testing.T) {
+	q := []float32{0.01, 0.02, 0.05, 0.07, 0.1, 0.15, 0.2, 0.4}
+	const k = 4
+	s := NewMultiOutcomeSampler(k, 0)
+	pi := s.chooseK(q)
+	s.p = extend(s.p, len(q))
+	counts := make([]int, len(q))
+	const n = 100000
+	for i := 0; i < n; i++ {
+		for j := range s.p {
+			s.p[j] = 0
+		}
+
+		s.sampleConditionalPoisson(q)
+		nSampled := 0
+		for j, x := range s.p {
+			if x > 0 {
+				if x != pi[j] {
+					t.Fatalf("action %d sampled with weight %v, expected %v", j, x, pi[j])
+				}
+
+				counts[j]++
+				nSampled++
+			}
+		}
+
+		if nSampled != k {
+			t.Fatalf("sampled %d actions, expected %d", nSampled, k)
+		}
+	}
+
+	for j, c := range counts {
+		freq := float64(c) / n
+		if math.Abs(freq-float64(pi[j])) > 0.01 {
+			t.Errorf("action %d sampled with frequency %v, expected %v", j, freq, pi[j])
+		}
+	}
+}
+
+func BenchmarkChooseK(b *testing.B) {
+	p0 := make([]float32, 50)
+	for i := range p0 {
+		p0[i] = float32(i+1) / 1275
+	}
+
+	s := NewMultiOutcomeSampler(6, 0)
+	for i := 0; i < b.N; i++ {
+		s.pool.free(s.chooseK(p0))
+	}
+}
+
+func TestSystematicSampling(t *testing.T) {
+	q := []float32{0.01, 0.02, 0.05, 0.07, 0.1, 0.15, 0.2, 0.4}
+	const k = 4
+	pi := make([]float32, len(q))
+	inclusionProbabilities(q, k, pi)
+	if total := f32.Sum(pi); math.Abs(float64(total)-k) > 1e-4 {
+		t.Errorf("inclusion probabilities sum to %v", total)
+	}
+
+	if pi[len(pi)-1] != 1.0 {
+		t.Errorf("expected the largest action to always be included, got %v", pi)
+	}
+
+	s := NewMultiOutcomeSampler(k, 0)
+	s.SetDesign(SystematicSampling)
+	s.p = extend(s.p, len(q))
+	counts := make([]int, len(q))
+	const n = 100000
+	for i := 0; i < n; i++ {
+		for j := range s.p {
+			s.p[j] = 0
+		}
+
+		s.sampleSystematic(q)
+		nSampled := 0
+		for j, x := range s.p {
+			if x > 0 {
+				if x != pi[j] {
+					t.Fatalf("action %d sampled with weight %v, expected %v", j, x, pi[j])
+				}
+
+				counts[j]++
+				nSampled++
+			}
+		}
+
+		if nSampled != k {
+			t.Fatalf("sampled %d actions, expected %d", nSampled, k)
+		}
+	}
+
+	for j, c := range counts {
+		freq := float64(c) / n
+		if math.Abs(freq-float64(pi[j])) > 0.01 {
+			t.Errorf("action %d sampled with frequency %v, expected %v", j, freq, pi[j])
+		}
 	}
 }
@@ -9,25 +9,45 @@ import (
 // OutcomeSampler implements cfr.Sampler by sampling one player action
 // according to the current strategy.
 type OutcomeSampler struct {
-	eps float32
+	sp  cfr.StrategyProfile
+	eps Schedule
 	rng *rand.Rand
 	p   []float32
 }
 
 func NewOutcomeSampler(explorationEps float32) *OutcomeSampler {
+	return NewScheduledOutcomeSampler(nil, ConstantSchedule(explorationEps))
+}
+
+// NewScheduledOutcomeSampler returns an OutcomeSampler whose exploration
+// epsilon follows the given schedule. It is evaluated at the iteration of
+// the SampleContext when used as a cfr.ContextSampler, as MCCFR does, and
+// otherwise at the current iteration of sp. sp may be nil if the sampler
+// is only used by MCCFR, or the schedule is constant.
+func NewScheduledOutcomeSampler(sp cfr.StrategyProfile, explorationEps Schedule) *OutcomeSampler {
 	return &OutcomeSampler{
+		sp:  sp,
 		eps: explorationEps,
 		rng: rand.New(rand.NewSource(rand.Int63())),
 	}
 }
 
 func (os *OutcomeSampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) []float32 {
+	return os.SampleWithContext(node, policy, cfr.SampleContext{})
+}
+
+// SampleWithContext implements cfr.ContextSampler, evaluating the
+// exploration schedule at sc.Iter.
+func (os *OutcomeSampler) SampleWithContext(node cfr.GameTreeNode, policy cfr.NodePolicy, sc cfr.SampleContext) []float32 {
 	nChildren := node.NumChildren()
+	mask := node.LegalActions()
+	nLegal := cfr.NumLegal(mask, nChildren)
+	eps := os.eps.Value(scheduleIter(sc, os.sp, os.eps))
 
 	var selected int
-	p := policy.GetStrategy()
-	if os.rng.Float32() < os.eps {
-		selected = os.rng.Intn(nChildren)
+	p := policy.GetLegalStrategy(mask)
+	if os.rng.Float32() < eps {
+		selected = nthLegal(mask, os.rng.Intn(nLegal))
 	} else {
 		selected = SampleOne(p, os.rng.Float32())
 	}
@@ -37,8 +57,8 @@ func (os *OutcomeSampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) [
 		os.p[i] = 0 // memclr
 	}
 
-	q := os.eps * (1.0 / float32(nChildren)) // Sampled due to exploration.
-	q += (1.0 - os.eps) * p[selected]        // Sampled due to strategy.
+	q := eps * (1.0 / float32(nLegal)) // Sampled due to exploration.
+	q += (1.0 - eps) * p[selected]     // Sampled due to strategy.
 
 	os.p[selected] = q
 	return os.p
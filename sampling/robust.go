@@ -6,8 +6,8 @@ import (
 	"github.com/tam0705/go-cfr"
 )
 
-// RobustSampler implements cfr.Sampler by sampling a fixed number of actions
-// uniformly randomly.
+// RobustSampler implements cfr.Sampler by sampling a fixed number of legal
+// actions uniformly randomly.
 type RobustSampler struct {
 	p   []float32
 	k   int
@@ -24,26 +24,35 @@ func NewRobustSampler(k int) *RobustSampler {
 func (rs *RobustSampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) []float32 {
 	nChildren := node.NumChildren()
 	rs.p = extend(rs.p, nChildren)
-
-	if nChildren <= rs.k {
-		for i := range rs.p[:nChildren] {
-			rs.p[i] = 1.0
+	mask := node.LegalActions()
+	nLegal := cfr.NumLegal(mask, nChildren)
+
+	if nLegal <= rs.k {
+		for i := range rs.p {
+			if cfr.IsLegal(mask, i) {
+				rs.p[i] = 1.0
+			} else {
+				rs.p[i] = 0
+			}
 		}
 
-		return rs.p[:nChildren]
+		return rs.p
 	}
 
+	// Shuffle the sampling probabilities among the first nLegal slots,
+	// then spread them out over the legal actions.
 	for i := 0; i < rs.k; i++ {
-		rs.p[i] = float32(rs.k) / float32(nChildren)
+		rs.p[i] = float32(rs.k) / float32(nLegal)
 	}
 
 	for i := rs.k; i < nChildren; i++ {
 		rs.p[i] = 0
 	}
 
-	rs.rng.Shuffle(nChildren, func(i, j int) {
+	rs.rng.Shuffle(nLegal, func(i, j int) {
 		rs.p[i], rs.p[j] = rs.p[j], rs.p[i]
 	})
 
+	spreadLegal(rs.p, mask, nLegal)
 	return rs.p
 }
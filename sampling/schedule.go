@@ -0,0 +1,94 @@
+package sampling
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// Schedule determines the value of a sampling parameter, such as the
+// exploration epsilon, as a function of the current training iteration.
+type Schedule interface {
+	Value(iter int) float32
+}
+
+// ConstantSchedule implements Schedule with a fixed value.
+type ConstantSchedule float32
+
+func (s ConstantSchedule) Value(iter int) float32 {
+	return float32(s)
+}
+
+// LinearSchedule implements Schedule by interpolating linearly from Start
+// (at iteration 0) to End (at iteration Iters), and then staying at End.
+type LinearSchedule struct {
+	Start, End float32
+	Iters      int
+}
+
+func (s LinearSchedule) Value(iter int) float32 {
+	if iter >= s.Iters {
+		return s.End
+	}
+
+	frac := float32(iter) / float32(s.Iters)
+	return s.Start + frac*(s.End-s.Start)
+}
+
+// ExponentialSchedule implements Schedule by decaying from Start by a
+// factor of Decay each iteration, but never below Min.
+type ExponentialSchedule struct {
+	Start, Decay, Min float32
+}
+
+func (s ExponentialSchedule) Value(iter int) float32 {
+	x := s.Start * float32(math.Pow(float64(s.Decay), float64(iter)))
+	return maxF32(x, s.Min)
+}
+
+// InverseSqrtSchedule implements Schedule by decaying as Start / sqrt(t),
+// but never below Min.
+type InverseSqrtSchedule struct {
+	Start, Min float32
+}
+
+func (s InverseSqrtSchedule) Value(iter int) float32 {
+	if iter < 1 {
+		iter = 1
+	}
+
+	x := s.Start / float32(math.Sqrt(float64(iter)))
+	return maxF32(x, s.Min)
+}
+
+// scheduleIter returns the iteration at which to evaluate the given
+// schedules: the one in the SampleContext if it is known, or else the
+// current iteration of sp. Without either, only constant schedules can be
+// evaluated, and it panics for any other.
+func scheduleIter(sc cfr.SampleContext, sp cfr.StrategyProfile, schedules ...Schedule) int {
+	// MCCFR starts at iteration 1, so 0 is a SampleContext from outside it.
+	if sc.Iter > 0 {
+		return sc.Iter
+	}
+
+	if sp != nil {
+		return sp.Iter()
+	}
+
+	for _, s := range schedules {
+		if _, ok := s.(ConstantSchedule); !ok {
+			panic(fmt.Errorf("sampling: %T needs a StrategyProfile, or a SampleContext from MCCFR", s))
+		}
+	}
+
+	return 0
+}
+
+func maxF32(x, y float32) float32 {
+	if x > y {
+		return x
+	}
+
+	return y
+}
@@ -0,0 +1,77 @@
+package sampling
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+)
+
+func TestSchedules(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schedule Schedule
+		iter     int
+		expected float32
+	}{
+		{"constant", ConstantSchedule(0.3), 1000, 0.3},
+		{"linear start", LinearSchedule{Start: 0.6, End: 0.1, Iters: 100}, 0, 0.6},
+		{"linear midway", LinearSchedule{Start: 0.6, End: 0.1, Iters: 100}, 50, 0.35},
+		{"linear end", LinearSchedule{Start: 0.6, End: 0.1, Iters: 100}, 500, 0.1},
+		{"exponential", ExponentialSchedule{Start: 1.0, Decay: 0.5, Min: 0.01}, 2, 0.25},
+		{"exponential floor", ExponentialSchedule{Start: 1.0, Decay: 0.5, Min: 0.01}, 100, 0.01},
+		{"inverse sqrt", InverseSqrtSchedule{Start: 1.0, Min: 0.01}, 16, 0.25},
+		{"inverse sqrt floor", InverseSqrtSchedule{Start: 1.0, Min: 0.01}, 1000000, 0.01},
+	}
+
+	for _, tc := range testCases {
+		if v := tc.schedule.Value(tc.iter); math.Abs(float64(v-tc.expected)) > 1e-6 {
+			t.Errorf("%s: expected %v at iter %d, got %v", tc.name, tc.expected, tc.iter, v)
+		}
+	}
+}
+
+// fixedPolicy is a cfr.NodePolicy with a fixed current strategy.
+type fixedPolicy struct {
+	cfr.NodePolicy
+	strategy []float32
+}
+
+func (p *fixedPolicy) GetLegalStrategy(mask []bool) []float32 { return p.strategy }
+
+func TestScheduleUsesSampleContext(t *testing.T) {
+	node := &playerNode{n: 2}
+	pol := &fixedPolicy{strategy: []float32{1, 0}}
+	s := NewScheduledOutcomeSampler(nil, LinearSchedule{Start: 1.0, End: 0.0, Iters: 10})
+
+	// At iteration 10 there is no exploration, so only the first action is sampled.
+	for i := 0; i < 100; i++ {
+		if p := s.SampleWithContext(node, pol, cfr.SampleContext{Iter: 10}); p[0] != 1.0 {
+			t.Fatalf("expected no exploration at iteration 10, got %v", p)
+		}
+	}
+
+	// Halfway through, eps = 0.5 and the first action is sampled with
+	// probability 0.5/2 + 0.5.
+	if p := s.SampleWithContext(node, pol, cfr.SampleContext{Iter: 5}); p[0] != 0 && p[0] != 0.75 {
+		t.Errorf("expected exploration eps 0.5 at iteration 5, got %v", p)
+	}
+}
+
+func TestScheduleWithoutIteration(t *testing.T) {
+	node := &playerNode{n: 2}
+	pol := &fixedPolicy{strategy: []float32{1, 0}}
+
+	if p := NewOutcomeSampler(0.5).Sample(node, pol); p[0]+p[1] == 0 {
+		t.Errorf("expected a constant schedule to sample without a StrategyProfile, got %v", p)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a linear schedule without a StrategyProfile")
+		}
+	}()
+
+	s := NewScheduledOutcomeSampler(nil, LinearSchedule{Start: 1.0, End: 0.0, Iters: 10})
+	s.Sample(node, pol)
+}
@@ -11,14 +11,19 @@ const tol = 1e-3
 
 // Sample one child of the given Chance node, according to its probability distribution.
 func SampleChanceNode(node cfr.GameTreeNode) (cfr.GameTreeNode, float64) {
-	x := rand.Float64()
+	i := sampleChanceIndex(node, rand.Float64())
+	return node.GetChild(i), node.GetChildProbability(i)
+}
+
+// sampleChanceIndex returns the first child i of node where the cumulative
+// probability of children [0, i] is greater than x.
+func sampleChanceIndex(node cfr.GameTreeNode, x float64) int {
 	var cumProb float64
 	n := node.NumChildren()
 	for i := 0; i < n; i++ {
-		p := node.GetChildProbability(i)
-		cumProb += p
+		cumProb += node.GetChildProbability(i)
 		if cumProb > x {
-			return node.GetChild(i), p
+			return i
 		}
 	}
 
@@ -27,7 +32,7 @@ func SampleChanceNode(node cfr.GameTreeNode) (cfr.GameTreeNode, float64) {
 			cumProb, node, n))
 	}
 
-	return node.GetChild(n - 1), node.GetChildProbability(n - 1)
+	return n - 1
 }
 
 // SampleOne returns the first element i of pv where sum(pv[:i]) > x.
@@ -47,6 +52,43 @@ func SampleOne(pv []float32, x float32) int {
 	return len(pv) - 1
 }
 
+// nthLegal returns the index of the nth action allowed by mask.
+func nthLegal(mask []bool, n int) int {
+	if mask == nil {
+		return n
+	}
+
+	for i, legal := range mask {
+		if legal {
+			if n == 0 {
+				return i
+			}
+
+			n--
+		}
+	}
+
+	panic(fmt.Errorf("mask has fewer than %d legal actions: %v", n+1, mask))
+}
+
+// spreadLegal moves the first nLegal entries of p onto the positions
+// of the legal actions in mask, zeroing the rest.
+func spreadLegal(p []float32, mask []bool, nLegal int) {
+	if mask == nil {
+		return
+	}
+
+	j := nLegal - 1
+	for i := len(p) - 1; i >= 0; i-- {
+		if mask[i] && j >= 0 {
+			p[i] = p[j]
+			j--
+		} else {
+			p[i] = 0
+		}
+	}
+}
+
 func extend(v []float32, n int) []float32 {
 	if n > len(v) {
 		needed := n - len(v)
@@ -0,0 +1,260 @@
+// Package sequenceform computes exact Nash equilibria of small two-player
+// zero-sum games, by solving the sequence-form linear program of Koller,
+// Megiddo and von Stengel with the simplex method.
+//
+// The size of the linear program is linear in the size of the game tree,
+// but the dense simplex implementation is only practical for games with
+// up to a few thousand sequences per player, such as Kuhn or Leduc poker.
+// The equilibria are intended as ground truth to compare CFR against.
+package sequenceform
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// Solution is an equilibrium of a two-player zero-sum game.
+type Solution struct {
+	// Profile holds the equilibrium strategy of each infoset, as both
+	// its current and average strategy.
+	Profile *cfr.PolicyTable
+	// Value is the expected utility of player 0 at equilibrium.
+	Value float64
+}
+
+// Solve computes a Nash equilibrium of the two-player zero-sum game rooted
+// at root. The game must have perfect recall, and all actions must be legal.
+func Solve(ctx context.Context, root cfr.GameTreeNode) (*Solution, error) {
+	g := &sequenceGame{
+		infoSetsByKey: [2]map[string]*infoSet{{}, {}},
+		numSequences:  [2]int{1, 1},
+		payoffs:       make(map[[2]int]float64),
+	}
+
+	if err := g.visit(ctx, root, [2]int{}, 1.0); err != nil {
+		return nil, err
+	}
+
+	var strategies [2][]float64
+	var values [2]float64
+	for player := 0; player < 2; player++ {
+		var err error
+		strategies[player], values[player], err = g.solve(ctx, player)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if math.Abs(values[0]+values[1]) > 1e-6*math.Max(1, math.Abs(values[0])) {
+		return nil, fmt.Errorf("sequenceform: inconsistent game values %v and %v", values[0], -values[1])
+	}
+
+	profile := cfr.NewPolicyTable(cfr.DiscountParams{})
+	for player := 0; player < 2; player++ {
+		for _, is := range g.infoSets[player] {
+			setStrategy(profile, is.key, is.strategy(strategies[player]))
+		}
+	}
+
+	return &Solution{Profile: profile, Value: values[0]}, nil
+}
+
+// setStrategy sets both the current and the average strategy of an infoset.
+func setStrategy(profile *cfr.PolicyTable, key string, strat []float32) {
+	profile.SetStrategy(key, append([]float32(nil), strat...))
+	profile.SetStrategySum(key, strat)
+}
+
+// infoSet is an information set, and the sequences that extend it.
+type infoSet struct {
+	key string
+	// parent is the player's sequence leading to the infoset.
+	parent int
+	// first is the sequence of the infoset's first action, and the
+	// others follow it.
+	first    int
+	nActions int
+}
+
+// strategy returns the behavioral strategy at the infoset, given the
+// realization plan x of its player.
+func (is *infoSet) strategy(x []float64) []float32 {
+	strat := make([]float32, is.nActions)
+	if reach := x[is.parent]; reach > tolerance {
+		var total float32
+		for i := range strat {
+			strat[i] = float32(math.Max(x[is.first+i], 0) / reach)
+			total += strat[i]
+		}
+		for i := range strat {
+			strat[i] /= total
+		}
+	} else {
+		// The infoset is never reached, so any strategy is optimal.
+		for i := range strat {
+			strat[i] = 1.0 / float32(is.nActions)
+		}
+	}
+
+	return strat
+}
+
+// sequenceGame is the sequence form of a game. Sequence 0 of
+// each player is the empty sequence.
+type sequenceGame struct {
+	infoSets      [2][]*infoSet
+	infoSetsByKey [2]map[string]*infoSet
+	numSequences  [2]int
+	// payoffs is the expected utility of player 0 at the terminal nodes
+	// reached by each pair of sequences, weighted by chance.
+	payoffs map[[2]int]float64
+}
+
+// visit adds the subtree rooted at node, which is reached by the given
+// sequences with the given chance probability, to the sequence form.
+func (g *sequenceGame) visit(ctx context.Context, node cfr.GameTreeNode, sequences [2]int, chanceProb float64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	switch node.Type() {
+	case cfr.TerminalNodeType:
+		u0, u1 := node.Utility(0), node.Utility(1)
+		if math.Abs(u0+u1) > 1e-9*math.Max(1, math.Abs(u0)) {
+			return fmt.Errorf("sequenceform: game is not zero-sum: utilities %v, %v at %v", u0, u1, node)
+		}
+
+		g.payoffs[sequences] += chanceProb * u0
+		return nil
+	case cfr.ChanceNodeType:
+		for i := 0; i < node.NumChildren(); i++ {
+			if p := node.GetChildProbability(i); p > 0 {
+				child := node.GetChild(i)
+				if err := g.visit(ctx, child, sequences, chanceProb*p); err != nil {
+					return err
+				}
+				child.Close()
+			}
+		}
+
+		return nil
+	}
+
+	player := node.Player()
+	if player != 0 && player != 1 {
+		return fmt.Errorf("sequenceform: invalid player %d at %v", player, node)
+	}
+
+	nChildren := node.NumChildren()
+	if cfr.NumLegal(node.LegalActions(), nChildren) < nChildren {
+		return fmt.Errorf("sequenceform: illegal actions are not supported: %v", node)
+	}
+
+	key := string(node.InfoSetKey(player))
+	is, ok := g.infoSetsByKey[player][key]
+	if !ok {
+		is = &infoSet{
+			key:      key,
+			parent:   sequences[player],
+			first:    g.numSequences[player],
+			nActions: nChildren,
+		}
+		g.numSequences[player] += nChildren
+		g.infoSets[player] = append(g.infoSets[player], is)
+		g.infoSetsByKey[player][key] = is
+	} else if is.nActions != nChildren {
+		return fmt.Errorf("sequenceform: infoset %q has %d actions, but node has %d children: %v",
+			key, is.nActions, nChildren, node)
+	} else if is.parent != sequences[player] {
+		return fmt.Errorf("sequenceform: game does not have perfect recall at infoset %q: %v", key, node)
+	}
+
+	for i := 0; i < nChildren; i++ {
+		child := node.GetChild(i)
+		next := sequences
+		next[player] = is.first + i
+		if err := g.visit(ctx, child, next, chanceProb); err != nil {
+			return err
+		}
+		child.Close()
+	}
+
+	return nil
+}
+
+// solve returns the realization plan maximizing the given player's
+// guaranteed utility, and its value.
+//
+// With x the player's realization plan, constrained by Ex = e and x >= 0,
+// and M the player's payoffs for each pair of sequences, the opponent's
+// best response minimizes x'My subject to Fy = f and y >= 0. Its dual is
+// to maximize f'q subject to F'q <= M'x, so the player solves
+//
+//	max f'q  subject to  F'q - M'x <= 0,  Ex = e,  x >= 0,
+//
+// which in standard form has variables (x, q+, q-, s) with q = q+ - q-
+// and slack variables s.
+func (g *sequenceGame) solve(ctx context.Context, player int) ([]float64, float64, error) {
+	opponent := 1 - player
+	nx, ny := g.numSequences[player], g.numSequences[opponent]
+	nq := 1 + len(g.infoSets[opponent])
+	qPlus, qMinus, slack := nx, nx+nq, nx+2*nq
+	nVars := nx + 2*nq + ny
+
+	nRows := ny + 1 + len(g.infoSets[player])
+	a := make([][]float64, nRows)
+	for i := range a {
+		a[i] = make([]float64, nVars)
+	}
+	b := make([]float64, nRows)
+
+	// F'q - M'x + s = 0, with a row for each of the opponent's sequences.
+	addQ := func(k, seq int, coef float64) {
+		a[seq][qPlus+k] += coef
+		a[seq][qMinus+k] -= coef
+	}
+	addQ(0, 0, 1)
+	for k, is := range g.infoSets[opponent] {
+		addQ(k+1, is.parent, -1)
+		for i := 0; i < is.nActions; i++ {
+			addQ(k+1, is.first+i, 1)
+		}
+	}
+
+	for seqs, u := range g.payoffs {
+		x, y := seqs[player], seqs[opponent]
+		if player == 1 {
+			u = -u
+		}
+		a[y][x] -= u
+	}
+
+	for y := 0; y < ny; y++ {
+		a[y][slack+y] = 1
+	}
+
+	// Ex = e, with a row for the empty sequence and for each infoset.
+	a[ny][0] = 1
+	b[ny] = 1
+	for k, is := range g.infoSets[player] {
+		row := a[ny+1+k]
+		row[is.parent] = -1
+		for i := 0; i < is.nActions; i++ {
+			row[is.first+i] = 1
+		}
+	}
+
+	c := make([]float64, nVars)
+	c[qPlus] = 1
+	c[qMinus] = -1
+
+	solution, value, err := simplex(ctx, a, b, c)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return solution[:nx], value, nil
+}
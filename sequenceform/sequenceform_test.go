@@ -0,0 +1,183 @@
+package sequenceform
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+	"github.com/tam0705/go-cfr/efg"
+	"github.com/tam0705/go-cfr/kuhn"
+	"github.com/tam0705/go-cfr/leduc"
+	"github.com/tam0705/go-cfr/normalform"
+	"github.com/tam0705/go-cfr/tree"
+)
+
+func checkStrategy(t *testing.T, profile cfr.StrategyProfile, key string, want []float32) {
+	t.Helper()
+	policy, ok := profile.GetPolicyByKey(key)
+	if !ok {
+		t.Errorf("%q: no policy", key)
+		return
+	}
+
+	for _, strat := range [][]float32{policy.GetStrategy(), policy.GetAverageStrategy()} {
+		for i, p := range strat {
+			if math.Abs(float64(p-want[i])) > 1e-6 {
+				t.Errorf("%q: expected strategy %v, got %v", key, want, strat)
+				break
+			}
+		}
+	}
+}
+
+func TestKuhn(t *testing.T) {
+	solution, err := Solve(context.Background(), kuhn.NewGame())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(solution.Value-kuhn.GAME_VALUE) > 1e-9 {
+		t.Errorf("expected game value %v, got %v", kuhn.GAME_VALUE, solution.Value)
+	}
+
+	if ev := tree.ExpectedValue(kuhn.NewGame(), tree.ProfileAverageStrategy(solution.Profile)); math.Abs(ev-kuhn.GAME_VALUE) > 1e-6 {
+		t.Errorf("expected profile value %v, got %v", kuhn.GAME_VALUE, ev)
+	}
+
+	if n := len(solution.Profile.PoliciesByKey); n != 12 {
+		t.Errorf("expected 12 infosets, got %d", n)
+	}
+
+	// Player 1's equilibrium strategy is unique.
+	for key, want := range map[string][]float32{
+		"Jp": {2. / 3, 1. / 3},
+		"Jb": {1, 0},
+		"Qp": {1, 0},
+		"Qb": {2. / 3, 1. / 3},
+		"Kp": {0, 1},
+		"Kb": {0, 1},
+	} {
+		checkStrategy(t, solution.Profile, key, want)
+	}
+}
+
+func TestNormalForm(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		game  *normalform.Game
+		want  [][]float32
+		value float64
+	}{
+		{"rock-paper-scissors", normalform.RockPaperScissors(),
+			[][]float32{{1. / 3, 1. / 3, 1. / 3}, {1. / 3, 1. / 3, 1. / 3}}, 0},
+		{"biased pennies", normalform.NewZeroSum([][]float64{{2, -1}, {-1, 1}}),
+			[][]float32{{0.4, 0.6}, {0.4, 0.6}}, 0.2},
+		// Both players have a dominant action.
+		{"saddle point", normalform.NewZeroSum([][]float64{{1, 2}, {0, -1}}),
+			[][]float32{{1, 0}, {1, 0}}, 1},
+	} {
+		solution, err := Solve(context.Background(), tc.game.Root())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if math.Abs(solution.Value-tc.value) > 1e-9 {
+			t.Errorf("%s: expected game value %v, got %v", tc.name, tc.value, solution.Value)
+		}
+
+		for player, want := range tc.want {
+			is := normalform.InfoSet{Player: player}
+			checkStrategy(t, solution.Profile, string(is.Key()), want)
+		}
+	}
+}
+
+func TestLeduc(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping Leduc solve in short mode")
+	}
+
+	solution, err := Solve(context.Background(), leduc.NewGame())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(solution.Value-leduc.GAME_VALUE) > 1e-3 {
+		t.Errorf("expected game value %v, got %v", leduc.GAME_VALUE, solution.Value)
+	}
+
+	// The game value is attained by the returned profile.
+	if ev := tree.ExpectedValue(leduc.NewGame(), tree.ProfileAverageStrategy(solution.Profile)); math.Abs(ev-solution.Value) > 1e-5 {
+		t.Errorf("expected profile value %v, got %v", solution.Value, ev)
+	}
+
+	// CFR converges to the same value.
+	compiled, err := tree.Compile(context.Background(), leduc.NewGame(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := tree.NewCompiledCFR(compiled, cfr.DiscountParams{LinearWeighting: true})
+	for i := 0; i < 2000; i++ {
+		c.Run()
+	}
+
+	profile := cfr.NewPolicyTable(cfr.DiscountParams{})
+	for id, key := range compiled.InfoSetKeys {
+		setStrategy(profile, string(key), c.GetAverageStrategy(id))
+	}
+
+	if ev := tree.ExpectedValue(leduc.NewGame(), tree.ProfileAverageStrategy(profile)); math.Abs(ev-solution.Value) > 0.01 {
+		t.Errorf("expected CFR value %v, got %v", solution.Value, ev)
+	}
+}
+
+const imperfectRecall = `EFG 2 R "Forgetful" { "A" "B" }
+p "" 1 1 "" { "l" "r" } 0
+p "" 1 2 "" { "x" "y" } 0
+t "" 1 "" { 1 -1 }
+t "" 2 "" { 0 0 }
+p "" 1 2 "" { "x" "y" } 0
+t "" 2
+t "" 1
+`
+
+func TestErrors(t *testing.T) {
+	forgetful, err := efg.Parse(strings.NewReader(imperfectRecall))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, tc := range []struct {
+		name string
+		ctx  context.Context
+		root cfr.GameTreeNode
+		err  string
+	}{
+		{"general-sum", context.Background(),
+			normalform.RandomBimatrix(rand.New(rand.NewSource(1)), 2, 2).Root(), "not zero-sum"},
+		{"three players", context.Background(),
+			normalform.NewGame([]int{1, 1, 2}, make([]float64, 6)).Root(), "invalid player 2"},
+		{"imperfect recall", context.Background(), forgetful.Root(), "perfect recall"},
+		{"cancelled", ctx, kuhn.NewGame(), context.Canceled.Error()},
+	} {
+		_, err := Solve(tc.ctx, tc.root)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.name, tc.err, err)
+		}
+	}
+}
+
+func BenchmarkKuhn(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := Solve(context.Background(), kuhn.NewGame()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package sequenceform
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// tolerance is the numerical tolerance of the simplex method.
+const tolerance = 1e-9
+
+// epsilon is the magnitude below which entries of the tableau are zeroed.
+const epsilon = 1e-13
+
+// maxDegeneratePivots is the number of consecutive pivots that do not
+// improve the objective after which Bland's rule is used to avoid cycling.
+const maxDegeneratePivots = 50
+
+var (
+	errInfeasible    = errors.New("sequenceform: linear program is infeasible")
+	errUnbounded     = errors.New("sequenceform: linear program is unbounded")
+	errMaxIterations = errors.New("sequenceform: simplex did not converge")
+)
+
+// tableau is a dense simplex tableau. The first m rows are the constraints,
+// and the last row holds the reduced costs of each column, and the current
+// value of the objective in its last column.
+type tableau struct {
+	rows  [][]float64
+	basis []int
+}
+
+func (t *tableau) m() int {
+	return len(t.basis)
+}
+
+// rhs is the index of the last column, which holds the right-hand sides.
+func (t *tableau) rhs() int {
+	return len(t.rows[0]) - 1
+}
+
+// objective returns the reduced cost row.
+func (t *tableau) objective() []float64 {
+	return t.rows[t.m()]
+}
+
+// pivot makes column c basic in row r.
+func (t *tableau) pivot(r, c int) {
+	row := t.rows[r]
+	scale := 1.0 / row[c]
+	for j := range row {
+		row[j] *= scale
+	}
+	row[c] = 1.0
+
+	for i, other := range t.rows {
+		if i == r || other[c] == 0 {
+			continue
+		}
+
+		f := other[c]
+		for j, x := range row {
+			if x != 0 {
+				other[j] -= f * x
+				if math.Abs(other[j]) < epsilon {
+					// Flush rounding errors, which would otherwise
+					// accumulate and may cause cycling.
+					other[j] = 0
+				}
+			}
+		}
+		other[c] = 0
+	}
+
+	t.basis[r] = c
+}
+
+// enteringColumn returns the column among the first n with the most
+// negative reduced cost, or with Bland's rule the first with a negative
+// reduced cost. It returns -1 if there is none, and the tableau is optimal.
+func (t *tableau) enteringColumn(n int, bland bool) int {
+	obj := t.objective()
+	best, bestCost := -1, -tolerance
+	for j := 0; j < n; j++ {
+		if obj[j] < bestCost {
+			best, bestCost = j, obj[j]
+			if bland {
+				break
+			}
+		}
+	}
+
+	return best
+}
+
+// leavingRow returns the row that limits how far column c can enter the
+// basis, breaking ties by the smallest basic column. It returns -1 if
+// the column is unbounded.
+func (t *tableau) leavingRow(c int) (int, float64) {
+	rhs := t.rhs()
+	best, bestRatio := -1, math.Inf(1)
+	for i := 0; i < t.m(); i++ {
+		x := t.rows[i][c]
+		if x <= tolerance {
+			continue
+		}
+
+		// Rounding errors may make the right-hand side slightly negative.
+		ratio := math.Max(t.rows[i][rhs], 0) / x
+		if ratio < bestRatio-tolerance || (ratio <= bestRatio+tolerance && t.basis[i] < t.basis[best]) {
+			best, bestRatio = i, ratio
+		}
+	}
+
+	return best, bestRatio
+}
+
+// optimize pivots until the objective is optimal, only letting the
+// first n columns enter the basis.
+func (t *tableau) optimize(ctx context.Context, n int) error {
+	maxIter := 50 * (t.m() + len(t.rows[0]))
+	degenerate := 0
+	for iter := 0; iter < maxIter; iter++ {
+		if iter%64 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		c := t.enteringColumn(n, degenerate > maxDegeneratePivots)
+		if c < 0 {
+			return nil
+		}
+
+		r, ratio := t.leavingRow(c)
+		if r < 0 {
+			return errUnbounded
+		}
+
+		if ratio <= tolerance {
+			degenerate++
+		} else {
+			degenerate = 0
+		}
+
+		t.pivot(r, c)
+	}
+
+	return errMaxIterations
+}
+
+// perturbation is the scale of the random perturbation of the right-hand
+// sides, which avoids the degenerate pivots that otherwise make the simplex
+// method stall on sequence-form programs.
+const perturbation = 1e-7
+
+// simplex maximizes c·x subject to ax = b and x >= 0, where b >= 0, with
+// the two-phase simplex method. It returns x and the optimal value.
+//
+// It first solves the program with randomly perturbed right-hand sides,
+// and then computes the solution with the original right-hand sides from
+// the optimal basis. If that solution is infeasible, it solves the
+// original program instead.
+func simplex(ctx context.Context, a [][]float64, b, c []float64) ([]float64, float64, error) {
+	rng := rand.New(rand.NewSource(1))
+	perturbed := make([]float64, len(b))
+	for i := range b {
+		perturbed[i] = b[i] + perturbation*(1+rng.Float64())
+	}
+
+	t, err := solveTableau(ctx, a, perturbed, c)
+	if err == nil {
+		if x, ok := t.solution(b, len(c)); ok {
+			var value float64
+			for j, cj := range c {
+				value += cj * x[j]
+			}
+
+			return x, value, nil
+		}
+	} else if err != errInfeasible {
+		return nil, 0, err
+	}
+
+	// The perturbation may have made the program infeasible, e.g. if it
+	// has redundant constraints, or changed the optimal basis.
+	if t, err = solveTableau(ctx, a, b, c); err != nil {
+		return nil, 0, err
+	}
+
+	x, _ := t.solution(b, len(c))
+	return x, t.objective()[t.rhs()], nil
+}
+
+// solution returns the basic solution of the first n variables with the
+// given right-hand sides, and whether it is feasible. The artificial
+// columns of the tableau hold the inverse of the basis.
+func (t *tableau) solution(b []float64, n int) ([]float64, bool) {
+	x := make([]float64, n)
+	feasible := true
+	for i, col := range t.basis {
+		var xi float64
+		for k, bk := range b {
+			xi += t.rows[i][n+k] * bk
+		}
+
+		if xi < -tolerance {
+			feasible = false
+		}
+
+		if col < n {
+			x[col] = math.Max(xi, 0)
+		} else if math.Abs(xi) > tolerance {
+			// An artificial variable remains in the basis.
+			feasible = false
+		}
+	}
+
+	return x, feasible
+}
+
+// solveTableau maximizes c·x subject to ax = b and x >= 0, where b >= 0,
+// with the two-phase simplex method, and returns the optimal tableau.
+func solveTableau(ctx context.Context, a [][]float64, b, c []float64) (*tableau, error) {
+	m, n := len(a), len(c)
+
+	// Phase 1 minimizes the sum of an artificial variable for each row,
+	// starting from the basis of artificial variables. Rows with a slack
+	// variable, which appears in no other row, start with it in the basis
+	// instead, which avoids many degenerate pivots.
+	t := &tableau{
+		rows:  make([][]float64, m+1),
+		basis: make([]int, m),
+	}
+	for i := range a {
+		row := make([]float64, n+m+1)
+		copy(row, a[i])
+		row[n+i] = 1
+		row[n+m] = b[i]
+		t.rows[i] = row
+		t.basis[i] = n + i
+	}
+	obj := make([]float64, n+m+1)
+	t.rows[m] = obj
+
+	for j := 0; j < n; j++ {
+		if i := unitRow(a, j); i >= 0 && t.basis[i] >= n {
+			t.pivot(i, j)
+		}
+	}
+
+	for i, row := range t.rows[:m] {
+		if t.basis[i] < n {
+			continue
+		}
+
+		for j := 0; j < n; j++ {
+			obj[j] -= row[j]
+		}
+		obj[n+m] -= row[n+m]
+	}
+
+	if err := t.optimize(ctx, n); err != nil {
+		return nil, err
+	}
+
+	if obj[n+m] < -tolerance*float64(m+1) {
+		return nil, errInfeasible
+	}
+
+	// Drive any remaining artificial variables out of the basis.
+	// Rows in which that is impossible are redundant.
+	for i := 0; i < m; i++ {
+		if t.basis[i] < n {
+			continue
+		}
+
+		for j := 0; j < n; j++ {
+			if math.Abs(t.rows[i][j]) > tolerance {
+				t.pivot(i, j)
+				break
+			}
+		}
+	}
+
+	// Phase 2 optimizes the original objective.
+	for j := range obj {
+		obj[j] = 0
+	}
+	for j := 0; j < n; j++ {
+		obj[j] = -c[j]
+	}
+	for i, col := range t.basis {
+		if col >= n || c[col] == 0 {
+			continue
+		}
+
+		for j, x := range t.rows[i] {
+			obj[j] += c[col] * x
+		}
+	}
+
+	if err := t.optimize(ctx, n); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// unitRow returns the only row in which column j of a is non-zero, if
+// that entry is positive, and otherwise -1.
+func unitRow(a [][]float64, j int) int {
+	result := -1
+	for i := range a {
+		if x := a[i][j]; x < 0 || (x > 0 && result >= 0) {
+			return -1
+		} else if x > 0 {
+			result = i
+		}
+	}
+
+	return result
+}
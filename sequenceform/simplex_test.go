@@ -0,0 +1,90 @@
+package sequenceform
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestSimplex(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		a     [][]float64
+		b, c  []float64
+		x     []float64
+		value float64
+		err   error
+	}{
+		{
+			// max 3x + 2y s.t. x + y <= 4, x + 3y <= 6.
+			name:  "inequalities",
+			a:     [][]float64{{1, 1, 1, 0}, {1, 3, 0, 1}},
+			b:     []float64{4, 6},
+			c:     []float64{3, 2, 0, 0},
+			x:     []float64{4, 0, 0, 2},
+			value: 12,
+		},
+		{
+			// max x + y s.t. x + 2y = 4, 3x + y = 7.
+			name:  "equalities",
+			a:     [][]float64{{1, 2}, {3, 1}},
+			b:     []float64{4, 7},
+			c:     []float64{1, 1},
+			x:     []float64{2, 1},
+			value: 3,
+		},
+		{
+			// The second row is twice the first.
+			name:  "redundant",
+			a:     [][]float64{{1, 1}, {2, 2}},
+			b:     []float64{1, 2},
+			c:     []float64{-1, 1},
+			x:     []float64{0, 1},
+			value: 1,
+		},
+		{
+			// x + y = 1 and x + y = 2.
+			name: "infeasible",
+			a:    [][]float64{{1, 1}, {1, 1}},
+			b:    []float64{1, 2},
+			c:    []float64{1, 0},
+			err:  errInfeasible,
+		},
+		{
+			// max x s.t. x - y = 1.
+			name: "unbounded",
+			a:    [][]float64{{1, -1}},
+			b:    []float64{1},
+			c:    []float64{1, 0},
+			err:  errUnbounded,
+		},
+	} {
+		x, value, err := simplex(context.Background(), tc.a, tc.b, tc.c)
+		if err != tc.err {
+			t.Errorf("%s: expected error %v, got %v", tc.name, tc.err, err)
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		if math.Abs(value-tc.value) > 1e-9 {
+			t.Errorf("%s: expected value %v, got %v", tc.name, tc.value, value)
+		}
+
+		for i := range x {
+			if math.Abs(x[i]-tc.x[i]) > 1e-9 {
+				t.Errorf("%s: expected solution %v, got %v", tc.name, tc.x, x)
+				break
+			}
+		}
+	}
+}
+
+func TestSimplexCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := simplex(ctx, [][]float64{{1, 1}}, []float64{1}, []float64{1, 0})
+	if err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,313 @@
+// Package state provides a simpler way to define games than implementing
+// cfr.GameTreeNode directly.
+//
+// A game implements State, which only describes the current position and
+// how to move from it, and NewNode adapts it to a cfr.GameTreeNode that
+// takes care of parent links, caching of children, chance sampling and
+// releasing resources. Games that can update a position in place may also
+// implement UndoState, and use NewUndoNode to avoid copying the State
+// at every node of the tree.
+package state
+
+import (
+	"encoding/gob"
+	"fmt"
+
+	"github.com/tam0705/go-cfr"
+	"github.com/tam0705/go-cfr/sampling"
+)
+
+const (
+	// CHANCE is the current player at chance nodes.
+	CHANCE = -1
+	// TERMINAL is the current player at terminal nodes.
+	TERMINAL = -2
+)
+
+// ChanceOutcome is an action at a chance node, and its probability.
+type ChanceOutcome struct {
+	Action      int
+	Probability float64
+}
+
+// State is a position in a game.
+type State interface {
+	// CurrentPlayer returns the player to act, or CHANCE or TERMINAL.
+	CurrentPlayer() int
+	// LegalActions returns the actions the current player may take.
+	// States in the same information set must have the same legal actions.
+	// The result is copied, so the State may reuse the slice.
+	LegalActions() []int
+	// ChanceOutcomes returns the possible actions at a chance node, which
+	// must have probabilities summing to 1.
+	ChanceOutcomes() []ChanceOutcome
+	// Apply returns the State after the given action. It must not
+	// modify this State.
+	Apply(action int) State
+	// Returns returns the utility of each player at a terminal node.
+	Returns() []float64
+	// InformationStateKey returns a key that uniquely identifies the
+	// information available to the given player, as cfr.InfoSet.Key.
+	InformationStateKey(player int) []byte
+}
+
+// UndoState is a State that can also be updated in place.
+type UndoState interface {
+	State
+	// ApplyInPlace updates this State with the given action.
+	ApplyInPlace(action int)
+	// Undo reverts the last action applied with ApplyInPlace.
+	Undo()
+}
+
+// StateNode implements cfr.GameTreeNode for a State.
+type StateNode struct {
+	parent *StateNode
+	// action is the action taken at the parent to reach this node.
+	action int
+	depth  int
+
+	// Each node holds its own state, unless the tree is in undo mode,
+	// in which case all nodes share one.
+	state  State
+	shared *sharedState
+
+	actions       []int
+	children      []StateNode
+	probabilities []float64
+}
+
+// sharedState is the single State of a tree in undo mode,
+// and the node it currently represents.
+type sharedState struct {
+	state  UndoState
+	cursor *StateNode
+}
+
+// NewNode returns the root of a game tree starting at the given State.
+// Each node of the tree holds the State returned by Apply.
+func NewNode(s State) *StateNode {
+	return &StateNode{state: s}
+}
+
+// NewUndoNode returns the root of a game tree starting at the given State.
+// All nodes of the tree share the State, which is updated with
+// ApplyInPlace and Undo to match whichever node is being accessed.
+//
+// This is cheapest for the depth-first traversals done by CFR, and
+// the State must not be used directly while the tree is in use.
+// Nodes of the tree must not be accessed concurrently.
+func NewUndoNode(s UndoState) *StateNode {
+	root := &StateNode{shared: &sharedState{state: s}}
+	root.shared.cursor = root
+	return root
+}
+
+// State returns the node's State. In undo mode it is only valid until
+// another node of the tree is accessed.
+func (k *StateNode) State() State {
+	if k.shared == nil {
+		return k.state
+	}
+
+	k.shared.moveTo(k)
+	return k.shared.state
+}
+
+// moveTo undoes and applies actions to move the shared
+// state from the current node to the given node.
+func (s *sharedState) moveTo(node *StateNode) {
+	if s.cursor == node {
+		return
+	}
+
+	target := node
+	var path []int
+	for node.depth > s.cursor.depth {
+		path = append(path, node.action)
+		node = node.parent
+	}
+
+	for s.cursor.depth > node.depth {
+		s.state.Undo()
+		s.cursor = s.cursor.parent
+	}
+
+	for s.cursor != node {
+		s.state.Undo()
+		s.cursor = s.cursor.parent
+		path = append(path, node.action)
+		node = node.parent
+	}
+
+	for i := len(path) - 1; i >= 0; i-- {
+		s.state.ApplyInPlace(path[i])
+	}
+
+	s.cursor = target
+}
+
+// String implements fmt.Stringer.
+func (k *StateNode) String() string {
+	return fmt.Sprint(k.State())
+}
+
+// Actions returns the action leading to each child.
+func (k *StateNode) Actions() []int {
+	if k.children == nil {
+		k.buildChildren()
+	}
+
+	return k.actions
+}
+
+// GetNode implements cfr.GameTreeNode. States are not addressable
+// by a history string, so it always returns nil.
+func (k *StateNode) GetNode(history string) cfr.GameTreeNode {
+	return nil
+}
+
+// Close implements cfr.GameTreeNode.
+func (k *StateNode) Close() {
+	k.actions = nil
+	k.children = nil
+	k.probabilities = nil
+}
+
+// Type implements cfr.GameTreeNode.
+func (k *StateNode) Type() cfr.NodeType {
+	switch k.State().CurrentPlayer() {
+	case TERMINAL:
+		return cfr.TerminalNodeType
+	case CHANCE:
+		return cfr.ChanceNodeType
+	default:
+		return cfr.PlayerNodeType
+	}
+}
+
+// NumChildren implements cfr.GameTreeNode.
+func (k *StateNode) NumChildren() int {
+	if k.children == nil {
+		k.buildChildren()
+	}
+
+	return len(k.children)
+}
+
+// GetChild implements cfr.GameTreeNode.
+func (k *StateNode) GetChild(i int) cfr.GameTreeNode {
+	if k.children == nil {
+		k.buildChildren()
+	}
+
+	return &k.children[i]
+}
+
+// Parent implements cfr.GameTreeNode.
+func (k *StateNode) Parent() cfr.GameTreeNode {
+	if k.parent == nil {
+		return nil
+	}
+
+	return k.parent
+}
+
+// GetChildProbability implements cfr.GameTreeNode.
+func (k *StateNode) GetChildProbability(i int) float64 {
+	if k.children == nil {
+		k.buildChildren()
+	}
+	if k.probabilities == nil {
+		return 0.0
+	}
+
+	return k.probabilities[i]
+}
+
+// SampleChild implements cfr.GameTreeNode.
+func (k *StateNode) SampleChild() (cfr.GameTreeNode, float64) {
+	return sampling.SampleChanceNode(k)
+}
+
+// Player implements cfr.GameTreeNode.
+func (k *StateNode) Player() int {
+	return k.State().CurrentPlayer()
+}
+
+// Utility implements cfr.GameTreeNode.
+func (k *StateNode) Utility(player int) float64 {
+	return k.State().Returns()[player]
+}
+
+// InfoSet implements cfr.GameTreeNode.
+func (k *StateNode) InfoSet(player int) cfr.InfoSet {
+	is := InfoSet(k.InfoSetKey(player))
+	return &is
+}
+
+// InfoSetKey implements cfr.GameTreeNode.
+func (k *StateNode) InfoSetKey(player int) []byte {
+	return k.State().InformationStateKey(player)
+}
+
+// LegalActions implements cfr.GameTreeNode. Only legal actions
+// have a child node, so all children are legal.
+func (k *StateNode) LegalActions() []bool {
+	return nil
+}
+
+func (k *StateNode) buildChildren() {
+	s := k.State()
+	switch s.CurrentPlayer() {
+	case TERMINAL:
+		return
+	case CHANCE:
+		outcomes := s.ChanceOutcomes()
+		k.actions = make([]int, len(outcomes))
+		k.probabilities = make([]float64, len(outcomes))
+		for i, outcome := range outcomes {
+			k.actions[i] = outcome.Action
+			k.probabilities[i] = outcome.Probability
+		}
+	default:
+		// In undo mode, the State may reuse the slice at other nodes.
+		k.actions = append([]int(nil), s.LegalActions()...)
+	}
+
+	k.children = make([]StateNode, len(k.actions))
+	for i, action := range k.actions {
+		child := &k.children[i]
+		child.parent = k
+		child.action = action
+		child.depth = k.depth + 1
+		if k.shared != nil {
+			child.shared = k.shared
+		} else {
+			child.state = s.Apply(action)
+		}
+	}
+}
+
+// InfoSet is the InformationStateKey of a State.
+type InfoSet string
+
+// Key implements cfr.InfoSet.
+func (is *InfoSet) Key() []byte {
+	return []byte(*is)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (is *InfoSet) MarshalBinary() ([]byte, error) {
+	return []byte(*is), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (is *InfoSet) UnmarshalBinary(buf []byte) error {
+	*is = InfoSet(buf)
+	return nil
+}
+
+func init() {
+	gob.Register(new(InfoSet))
+}
@@ -0,0 +1,268 @@
+package state
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+	"github.com/tam0705/go-cfr/internal/cfrtest"
+	"github.com/tam0705/go-cfr/kuhn"
+	"github.com/tam0705/go-cfr/sampling"
+	"github.com/tam0705/go-cfr/tree"
+)
+
+// kuhnState is Kuhn poker defined as a State, to compare with the kuhn package.
+type kuhnState struct {
+	// deal is an index into kuhn.DEALS, or -1 before the cards are dealt.
+	deal    int
+	history string
+}
+
+const kuhnActions = "pb"
+
+func newKuhnState() kuhnState {
+	return kuhnState{deal: -1}
+}
+
+func (s kuhnState) CurrentPlayer() int {
+	switch {
+	case s.deal < 0:
+		return CHANCE
+	case s.history == "pp" || s.history == "bp" || s.history == "bb" || len(s.history) == 3:
+		return TERMINAL
+	default:
+		return len(s.history) % 2
+	}
+}
+
+func (s kuhnState) LegalActions() []int {
+	return []int{0, 1}
+}
+
+func (s kuhnState) ChanceOutcomes() []ChanceOutcome {
+	outcomes := make([]ChanceOutcome, len(kuhn.DEALS))
+	for i := range outcomes {
+		outcomes[i] = ChanceOutcome{Action: i, Probability: 1.0 / float64(len(kuhn.DEALS))}
+	}
+
+	return outcomes
+}
+
+func (s kuhnState) Apply(action int) State {
+	if s.deal < 0 {
+		s.deal = action
+	} else {
+		s.history += kuhnActions[action : action+1]
+	}
+
+	return s
+}
+
+func (s kuhnState) Returns() []float64 {
+	cards := kuhn.DEALS[s.deal]
+	showdown := 1.0
+	if strings.IndexByte("JQK", byte(cards[0])) < strings.IndexByte("JQK", byte(cards[1])) {
+		showdown = -1.0
+	}
+
+	var u float64
+	switch s.history {
+	case "bp":
+		u = 1.0
+	case "pbp":
+		u = -1.0
+	case "pp":
+		u = showdown
+	default:
+		u = 2 * showdown
+	}
+
+	return []float64{u, -u}
+}
+
+func (s kuhnState) InformationStateKey(player int) []byte {
+	return append([]byte{byte(kuhn.DEALS[s.deal][player])}, s.history...)
+}
+
+// undoKuhnState is a kuhnState that is updated in place.
+type undoKuhnState struct {
+	kuhnState
+	applied int
+}
+
+func (s *undoKuhnState) ApplyInPlace(action int) {
+	s.kuhnState = s.kuhnState.Apply(action).(kuhnState)
+	s.applied++
+}
+
+func (s *undoKuhnState) Undo() {
+	if s.history == "" {
+		s.deal = -1
+	} else {
+		s.history = s.history[:len(s.history)-1]
+	}
+}
+
+func roots() map[string]func() cfr.GameTreeNode {
+	return map[string]func() cfr.GameTreeNode{
+		"apply": func() cfr.GameTreeNode { return NewNode(newKuhnState()) },
+		"undo":  func() cfr.GameTreeNode { return NewUndoNode(&undoKuhnState{kuhnState: newKuhnState()}) },
+	}
+}
+
+func TestGameTree(t *testing.T) {
+	for name, newRoot := range roots() {
+		root := newRoot()
+		if n := tree.CountNodes(root); n != 55 {
+			t.Errorf("%s: expected 55 nodes, got %d", name, n)
+		}
+		if n := tree.CountTerminalNodes(root); n != 30 {
+			t.Errorf("%s: expected 30 terminal nodes, got %d", name, n)
+		}
+		if n := tree.CountInfoSets(root); n != 12 {
+			t.Errorf("%s: expected 12 infosets, got %d", name, n)
+		}
+		if problems := tree.Validate(root); len(problems) > 0 {
+			t.Errorf("%s: invalid game tree: %v", name, problems)
+		}
+	}
+}
+
+// compareNodes checks that two game trees are identical.
+func compareNodes(t *testing.T, got, want cfr.GameTreeNode) {
+	t.Helper()
+	if got.Type() != want.Type() || got.NumChildren() != want.NumChildren() {
+		t.Fatalf("expected %v with %d children, got %v with %d children",
+			want.Type(), want.NumChildren(), got.Type(), got.NumChildren())
+	}
+
+	switch got.Type() {
+	case cfr.TerminalNodeType:
+		for player := 0; player < 2; player++ {
+			if got.Utility(player) != want.Utility(player) {
+				t.Errorf("%v: expected utility %v, got %v", want, want.Utility(player), got.Utility(player))
+			}
+		}
+	case cfr.ChanceNodeType:
+		for i := 0; i < got.NumChildren(); i++ {
+			if math.Abs(got.GetChildProbability(i)-want.GetChildProbability(i)) > 1e-9 {
+				t.Errorf("%v: expected probability %v, got %v",
+					want, want.GetChildProbability(i), got.GetChildProbability(i))
+			}
+		}
+	default:
+		player := got.Player()
+		if player != want.Player() || string(got.InfoSetKey(player)) != string(want.InfoSetKey(player)) {
+			t.Errorf("expected %v, got player %d with infoset %q", want, player, got.InfoSetKey(player))
+		}
+	}
+
+	for i := 0; i < got.NumChildren(); i++ {
+		compareNodes(t, got.GetChild(i), want.GetChild(i))
+	}
+}
+
+func TestCompareKuhn(t *testing.T) {
+	for _, newRoot := range roots() {
+		compareNodes(t, newRoot(), kuhn.NewGame())
+	}
+}
+
+// collectNodes returns all nodes of the tree, in pre-order.
+func collectNodes(node cfr.GameTreeNode) []cfr.GameTreeNode {
+	nodes := []cfr.GameTreeNode{node}
+	for i := 0; i < node.NumChildren(); i++ {
+		nodes = append(nodes, collectNodes(node.GetChild(i))...)
+	}
+
+	return nodes
+}
+
+func TestUndoRandomAccess(t *testing.T) {
+	s := &undoKuhnState{kuhnState: newKuhnState()}
+	nodes := collectNodes(NewUndoNode(s))
+	want := collectNodes(NewNode(newKuhnState()))
+	if s.applied != len(nodes)-1 {
+		t.Errorf("expected one ApplyInPlace per edge, got %d for %d nodes", s.applied, len(nodes))
+	}
+
+	// Accessing the nodes in any order moves the shared state to them.
+	for _, i := range rand.Perm(len(nodes)) {
+		got := nodes[i].(*StateNode).State().(*undoKuhnState).kuhnState
+		if expected := want[i].(*StateNode).State().(kuhnState); got != expected {
+			t.Fatalf("node %d: expected state %+v, got %+v", i, expected, got)
+		}
+	}
+}
+
+// reusedActionsState returns its legal actions in a slice that it reuses,
+// listing them in reverse order after an odd number of actions.
+type reusedActionsState struct {
+	undoKuhnState
+	buf []int
+}
+
+func (s *reusedActionsState) LegalActions() []int {
+	s.buf = append(s.buf[:0], 0, 1)
+	if len(s.history)%2 == 1 {
+		s.buf[0], s.buf[1] = 1, 0
+	}
+
+	return s.buf
+}
+
+func TestReusedLegalActions(t *testing.T) {
+	root := NewUndoNode(&reusedActionsState{undoKuhnState: undoKuhnState{kuhnState: newKuhnState()}})
+	node := root.GetChild(0).(*StateNode)
+	want := append([]int(nil), node.Actions()...)
+
+	// Building the children of descendants reuses the slice.
+	collectNodes(node)
+	if got := node.Actions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected actions %v, got %v", want, got)
+	}
+}
+
+func TestInfoSetMarshal(t *testing.T) {
+	node := NewNode(newKuhnState()).GetChild(2).GetChild(1)
+	is := node.InfoSet(1).(*InfoSet)
+	buf, err := is.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded InfoSet
+	if err := decoded.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded != *is || string(decoded.Key()) != "Jb" {
+		t.Errorf("expected %q, got %q", *is, decoded)
+	}
+}
+
+func TestMCCFR(t *testing.T) {
+	for name, newRoot := range roots() {
+		profile := cfr.NewPolicyTable(cfr.DiscountParams{LinearWeighting: true})
+		cfrtest.Train(profile, newRoot(), sampling.NewExternalSampler(), 20000)
+		cfrtest.CheckGameValue(t, name, newRoot(), profile, kuhn.GAME_VALUE, 0.01)
+	}
+}
+
+func BenchmarkMCCFR(b *testing.B) {
+	for name, newRoot := range roots() {
+		b.Run(name, func(b *testing.B) {
+			profile := cfr.NewPolicyTable(cfr.DiscountParams{})
+			opt := cfr.NewMCCFR(profile, sampling.NewExternalSampler())
+			root := newRoot()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				opt.Run(root)
+				profile.Update()
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package tree
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// CompiledTree is a flat, index-based representation of a finite game tree.
+//
+// Nodes are identified by their index in the arrays, with the root at 0.
+// The children of each node have contiguous indices, starting at ChildStart.
+type CompiledTree struct {
+	Type        []cfr.NodeType
+	Player      []int32
+	Parent      []int32
+	ChildStart  []int32
+	NumChildren []int32
+	// EdgeProb is the probability of reaching each node from its parent,
+	// if the parent is a chance node.
+	EdgeProb []float64
+	// EdgeLegal is whether the action leading to each node is legal.
+	EdgeLegal []bool
+	// Masked is whether some child of each node is not legal.
+	Masked []bool
+	// InfoSet is the index of each player node's InfoSet in InfoSetKeys,
+	// or -1 for other nodes.
+	InfoSet []int32
+	// UtilityStart is the index of each terminal node's utilities in
+	// Utility, or -1 for other nodes.
+	UtilityStart []int32
+	// Utility holds the utilities of each player at the terminal nodes.
+	Utility []float64
+	// InfoSetKeys is the key of each distinct InfoSet.
+	InfoSetKeys [][]byte
+	// NumActions is the number of actions at each InfoSet.
+	NumActions []int32
+
+	nPlayers    int
+	maxDepth    int
+	nodes       []compiledNode
+	infoSetByID map[string]int32
+}
+
+// Compile walks the finite game tree rooted at root once, and returns its
+// flat representation. Utilities are recorded for nPlayers players
+// (or 2 if nPlayers is not positive). Each node of the original tree is
+// closed after it has been visited. It returns an error if two nodes in
+// the same InfoSet have different numbers of children.
+func Compile(ctx context.Context, root cfr.GameTreeNode, nPlayers int) (*CompiledTree, error) {
+	if nPlayers <= 0 {
+		nPlayers = 2
+	}
+
+	t := &CompiledTree{
+		nPlayers:    nPlayers,
+		infoSetByID: make(map[string]int32),
+	}
+
+	// ids[d] is the index of the current node at depth d, and
+	// nVisited[d] is the number of its children visited so far.
+	var ids, nVisited []int32
+	var compileErr error
+	t.addNode()
+	err := Walk(ctx, root, WalkOptions{
+		PreOrder: func(node cfr.GameTreeNode, depth int) VisitAction {
+			var id int32
+			if depth > 0 {
+				parent := ids[depth-1]
+				i := nVisited[depth-1]
+				nVisited[depth-1]++
+				id = t.ChildStart[parent] + i
+				t.Parent[id] = parent
+			}
+
+			ids = append(ids[:depth], id)
+			nVisited = append(nVisited[:depth], 0)
+			if depth > t.maxDepth {
+				t.maxDepth = depth
+			}
+
+			if compileErr = t.compileNode(node, id); compileErr != nil {
+				return Stop
+			}
+
+			return Continue
+		},
+		CloseNodes: true,
+	})
+
+	if err == nil {
+		err = compileErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	t.nodes = make([]compiledNode, len(t.Type))
+	for i := range t.nodes {
+		t.nodes[i] = compiledNode{t, int32(i)}
+	}
+
+	return t, nil
+}
+
+// addNode appends space for a new node, and returns its index.
+func (t *CompiledTree) addNode() int32 {
+	t.Type = append(t.Type, cfr.TerminalNodeType)
+	t.Player = append(t.Player, -1)
+	t.Parent = append(t.Parent, -1)
+	t.ChildStart = append(t.ChildStart, 0)
+	t.NumChildren = append(t.NumChildren, 0)
+	t.EdgeProb = append(t.EdgeProb, 0)
+	t.EdgeLegal = append(t.EdgeLegal, true)
+	t.Masked = append(t.Masked, false)
+	t.InfoSet = append(t.InfoSet, -1)
+	t.UtilityStart = append(t.UtilityStart, -1)
+	return int32(len(t.Type) - 1)
+}
+
+// compileNode records the node with the given index, and reserves
+// indices for its children. It returns an error if the node is
+// inconsistent with others in the same InfoSet.
+func (t *CompiledTree) compileNode(node cfr.GameTreeNode, id int32) error {
+	nodeType := node.Type()
+	t.Type[id] = nodeType
+	if nodeType == cfr.TerminalNodeType {
+		t.UtilityStart[id] = int32(len(t.Utility))
+		for p := 0; p < t.nPlayers; p++ {
+			t.Utility = append(t.Utility, node.Utility(p))
+		}
+
+		return nil
+	}
+
+	// Reserve contiguous indices for all of the node's children.
+	nChildren := node.NumChildren()
+	t.NumChildren[id] = int32(nChildren)
+	t.ChildStart[id] = int32(len(t.Type))
+	for i := 0; i < nChildren; i++ {
+		t.addNode()
+	}
+
+	start := t.ChildStart[id]
+	if nodeType == cfr.ChanceNodeType {
+		for i := 0; i < nChildren; i++ {
+			t.EdgeProb[start+int32(i)] = node.GetChildProbability(i)
+		}
+
+		return nil
+	}
+
+	player := node.Player()
+	t.Player[id] = int32(player)
+	mask := node.LegalActions()
+	if mask != nil {
+		for i, legal := range mask {
+			t.EdgeLegal[start+int32(i)] = legal
+			if !legal {
+				t.Masked[id] = true
+			}
+		}
+	}
+
+	key := node.InfoSetKey(player)
+	infoSet, ok := t.infoSetByID[string(key)]
+	if !ok {
+		infoSet = int32(len(t.InfoSetKeys))
+		t.infoSetByID[string(key)] = infoSet
+		t.InfoSetKeys = append(t.InfoSetKeys, append([]byte(nil), key...))
+		t.NumActions = append(t.NumActions, int32(nChildren))
+	} else if t.NumActions[infoSet] != int32(nChildren) {
+		return fmt.Errorf("infoset %q has %d actions but node has %d children: %v",
+			key, t.NumActions[infoSet], nChildren, node)
+	}
+
+	t.InfoSet[id] = infoSet
+	return nil
+}
+
+// NumNodes returns the number of nodes in the tree.
+func (t *CompiledTree) NumNodes() int {
+	return len(t.Type)
+}
+
+// NumInfoSets returns the number of distinct InfoSets in the tree.
+func (t *CompiledTree) NumInfoSets() int {
+	return len(t.InfoSetKeys)
+}
+
+// Root returns the root of the tree, as a cfr.GameTreeNode.
+func (t *CompiledTree) Root() cfr.GameTreeNode {
+	return &t.nodes[0]
+}
+
+// Node returns the node with the given index, as a cfr.GameTreeNode.
+func (t *CompiledTree) Node(id int) cfr.GameTreeNode {
+	return &t.nodes[id]
+}
+
+// compiledNode implements cfr.GameTreeNode for a node of a CompiledTree.
+type compiledNode struct {
+	t  *CompiledTree
+	id int32
+}
+
+// String implements fmt.Stringer.
+func (n *compiledNode) String() string {
+	return fmt.Sprintf("compiled node %d", n.id)
+}
+
+// GetNode implements cfr.GameTreeNode. Compiled trees do not keep
+// the histories of their nodes, so it always returns nil.
+func (n *compiledNode) GetNode(history string) cfr.GameTreeNode {
+	return nil
+}
+
+// Type implements cfr.GameTreeNode.
+func (n *compiledNode) Type() cfr.NodeType {
+	return n.t.Type[n.id]
+}
+
+// Close implements cfr.GameTreeNode.
+func (n *compiledNode) Close() {}
+
+// NumChildren implements cfr.GameTreeNode.
+func (n *compiledNode) NumChildren() int {
+	return int(n.t.NumChildren[n.id])
+}
+
+// GetChild implements cfr.GameTreeNode.
+func (n *compiledNode) GetChild(i int) cfr.GameTreeNode {
+	return &n.t.nodes[n.t.ChildStart[n.id]+int32(i)]
+}
+
+// Parent implements cfr.GameTreeNode.
+func (n *compiledNode) Parent() cfr.GameTreeNode {
+	parent := n.t.Parent[n.id]
+	if parent < 0 {
+		return nil
+	}
+
+	return &n.t.nodes[parent]
+}
+
+// GetChildProbability implements cfr.GameTreeNode.
+func (n *compiledNode) GetChildProbability(i int) float64 {
+	return n.t.EdgeProb[n.t.ChildStart[n.id]+int32(i)]
+}
+
+// SampleChild implements cfr.GameTreeNode.
+func (n *compiledNode) SampleChild() (cfr.GameTreeNode, float64) {
+	start := n.t.ChildStart[n.id]
+	nChildren := n.t.NumChildren[n.id]
+	x := rand.Float64()
+	var cumProb float64
+	for i := start; i < start+nChildren; i++ {
+		cumProb += n.t.EdgeProb[i]
+		if cumProb > x {
+			return &n.t.nodes[i], n.t.EdgeProb[i]
+		}
+	}
+
+	last := start + nChildren - 1
+	return &n.t.nodes[last], n.t.EdgeProb[last]
+}
+
+// Player implements cfr.GameTreeNode.
+func (n *compiledNode) Player() int {
+	return int(n.t.Player[n.id])
+}
+
+// InfoSet implements cfr.GameTreeNode. Only the InfoSet of the acting
+// player is kept, so player must be Player().
+func (n *compiledNode) InfoSet(player int) cfr.InfoSet {
+	return &compiledInfoSet{key: n.InfoSetKey(player)}
+}
+
+// InfoSetKey implements cfr.GameTreeNode. Only the InfoSet of the acting
+// player is kept, so player must be Player().
+func (n *compiledNode) InfoSetKey(player int) []byte {
+	return n.t.InfoSetKeys[n.t.InfoSet[n.id]]
+}
+
+// LegalActions implements cfr.GameTreeNode.
+func (n *compiledNode) LegalActions() []bool {
+	if !n.t.Masked[n.id] {
+		return nil
+	}
+
+	start := n.t.ChildStart[n.id]
+	return n.t.EdgeLegal[start : start+n.t.NumChildren[n.id]]
+}
+
+// Utility implements cfr.GameTreeNode.
+func (n *compiledNode) Utility(player int) float64 {
+	return n.t.Utility[int(n.t.UtilityStart[n.id])+player]
+}
+
+type compiledInfoSet struct {
+	key []byte
+}
+
+func (is *compiledInfoSet) Key() []byte {
+	return is.key
+}
+
+func (is *compiledInfoSet) MarshalBinary() ([]byte, error) {
+	return is.key, nil
+}
+
+func (is *compiledInfoSet) UnmarshalBinary(buf []byte) error {
+	is.key = append([]byte(nil), buf...)
+	return nil
+}
+
+// InfoSetID returns the index of the InfoSet with the given key.
+func (t *CompiledTree) InfoSetID(key []byte) (int, bool) {
+	id, ok := t.infoSetByID[string(key)]
+	return int(id), ok
+}
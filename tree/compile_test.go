@@ -0,0 +1,170 @@
+package tree
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+	"github.com/tam0705/go-cfr/sampling"
+)
+
+func TestCompile(t *testing.T) {
+	root := newTestTree(3, 4)
+	compiled, err := Compile(context.Background(), root, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := compiled.NumNodes(); n != 1+3+9+27+81 {
+		t.Errorf("expected 121 nodes, got %d", n)
+	}
+
+	if *root.closed != int64(compiled.NumNodes()) {
+		t.Errorf("expected all %d nodes to be closed, got %d", compiled.NumNodes(), *root.closed)
+	}
+
+	// Player 0 observes the chance outcome, player 1 does not.
+	if n := compiled.NumInfoSets(); n != 3+3+27 {
+		t.Errorf("expected 33 infosets, got %d", n)
+	}
+
+	if problems := Validate(compiled.Root()); len(problems) > 0 {
+		t.Errorf("compiled tree is not valid: %v", problems)
+	}
+
+	compareNodes(t, newTestTree(3, 4), compiled.Root())
+}
+
+// raggedNode is a testNode where player 1 has one action fewer after
+// the last chance outcome, which it cannot observe.
+type raggedNode struct {
+	*testNode
+}
+
+func (n raggedNode) NumChildren() int {
+	nChildren := n.testNode.NumChildren()
+	if n.Type() == cfr.PlayerNodeType && n.Player() == 1 && int(n.history[0]-'a') == n.branching-1 {
+		return nChildren - 1
+	}
+
+	return nChildren
+}
+
+func (n raggedNode) GetChild(i int) cfr.GameTreeNode {
+	return raggedNode{n.testNode.GetChild(i).(*testNode)}
+}
+
+func TestCompileActionMismatch(t *testing.T) {
+	root := newTestTree(2, 3)
+	if _, err := Compile(context.Background(), raggedNode{root}, 2); err == nil {
+		t.Error("expected an error for an infoset with different numbers of actions")
+	}
+
+	if *root.closed == 0 {
+		t.Error("expected visited nodes to be closed")
+	}
+}
+
+func compareNodes(t *testing.T, expected, actual cfr.GameTreeNode) {
+	if expected.Type() != actual.Type() || expected.NumChildren() != actual.NumChildren() {
+		t.Fatalf("%v: expected %v node with %d children, got %v node with %d",
+			expected, expected.Type(), expected.NumChildren(), actual.Type(), actual.NumChildren())
+	}
+
+	switch expected.Type() {
+	case cfr.TerminalNodeType:
+		for p := 0; p < 2; p++ {
+			if expected.Utility(p) != actual.Utility(p) {
+				t.Errorf("%v: expected utility %v for player %d, got %v",
+					expected, expected.Utility(p), p, actual.Utility(p))
+			}
+		}
+	case cfr.ChanceNodeType:
+		for i := 0; i < expected.NumChildren(); i++ {
+			if expected.GetChildProbability(i) != actual.GetChildProbability(i) {
+				t.Errorf("%v: expected probability %v for child %d, got %v",
+					expected, expected.GetChildProbability(i), i, actual.GetChildProbability(i))
+			}
+		}
+	default:
+		p := expected.Player()
+		if p != actual.Player() || string(expected.InfoSetKey(p)) != string(actual.InfoSetKey(p)) {
+			t.Errorf("%v: expected player %d with infoset %q, got player %d with %q",
+				expected, p, expected.InfoSetKey(p), actual.Player(), actual.InfoSetKey(actual.Player()))
+		}
+	}
+
+	for i := 0; i < expected.NumChildren(); i++ {
+		child := actual.GetChild(i)
+		if child.Parent() != actual {
+			t.Errorf("%v: child %d has the wrong parent", expected, i)
+		}
+
+		compareNodes(t, expected.GetChild(i), child)
+	}
+}
+
+func TestCompiledCFR(t *testing.T) {
+	compiled, err := Compile(context.Background(), newTestTree(3, 3), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCompiledCFR(compiled, cfr.DiscountParams{LinearWeighting: true})
+	var ev float32
+	for i := 0; i < 1000; i++ {
+		ev = c.Run()
+	}
+
+	if c.Iter() != 1001 {
+		t.Errorf("expected iteration 1001, got %d", c.Iter())
+	}
+
+	// Player 1 moves last, and always takes the action worst for player 0.
+	if math.Abs(float64(ev)+1) > 1e-3 {
+		t.Errorf("expected game value -1, got %v", ev)
+	}
+
+	for _, key := range []string{"1:a", "1:b", "1:c"} {
+		id, ok := compiled.InfoSetID([]byte(key))
+		if !ok {
+			t.Fatalf("infoset %q not found", key)
+		}
+
+		if avgStrat := c.GetAverageStrategy(id); avgStrat[0] < 0.99 {
+			t.Errorf("%s: expected to converge to the first action, got %v", key, avgStrat)
+		}
+	}
+}
+
+// BenchmarkCFR compares CompiledCFR with MCCFR traversing the same tree.
+// Each iteration updates the strategies of both players.
+func BenchmarkCFR(b *testing.B) {
+	b.Run("Compiled", func(b *testing.B) {
+		compiled, err := Compile(context.Background(), newTestTree(4, 6), 2)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		c := NewCompiledCFR(compiled, cfr.DiscountParams{})
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			c.Run()
+		}
+	})
+
+	b.Run("MCCFR", func(b *testing.B) {
+		profile := cfr.NewPolicyTable(cfr.DiscountParams{})
+		opt := cfr.NewMCCFR(profile, sampling.NewExternalSampler())
+		opt.SetChanceSampler(sampling.NewEnumeratingChanceSampler())
+		root := newTestTree(4, 6)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for player := 0; player < 2; player++ {
+				opt.Run(root)
+				profile.Update()
+			}
+		}
+	})
+}
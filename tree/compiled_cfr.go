@@ -0,0 +1,248 @@
+package tree
+
+import (
+	"fmt"
+
+	"github.com/tam0705/go-cfr"
+	"github.com/tam0705/go-cfr/internal/f32"
+)
+
+// CompiledCFR runs vanilla (full-width) CFR directly on the arrays of a
+// CompiledTree. It bypasses the GameTreeNode interface and the map lookups
+// of a StrategyProfile, which makes it much faster for games that fit in memory.
+type CompiledCFR struct {
+	t      *CompiledTree
+	params cfr.DiscountParams
+	iter   int
+
+	// The values of InfoSet i are at [offset[i], offset[i]+NumActions[i])
+	// in each of the flat arrays below.
+	offset         []int32
+	strategy       []float32
+	regretSum      []float32
+	strategySum    []float32
+	strategyWeight []float32
+
+	// Scratch space for each depth of the tree.
+	values      [][]float32
+	legalStrats [][]float32
+}
+
+// NewCompiledCFR creates a new CompiledCFR for the given tree, with regrets
+// and average strategies discounted according to params.
+func NewCompiledCFR(t *CompiledTree, params cfr.DiscountParams) *CompiledCFR {
+	c := &CompiledCFR{
+		t:      t,
+		params: params,
+		iter:   1,
+		offset: make([]int32, len(t.NumActions)),
+	}
+
+	var n, maxActions int32
+	for i, nActions := range t.NumActions {
+		c.offset[i] = n
+		n += nActions
+		if nActions > maxActions {
+			maxActions = nActions
+		}
+	}
+
+	c.strategy = make([]float32, n)
+	c.regretSum = make([]float32, n)
+	c.strategySum = make([]float32, n)
+	c.strategyWeight = make([]float32, n)
+	for i, nActions := range t.NumActions {
+		strat := c.strategy[c.offset[i] : c.offset[i]+nActions]
+		for j := range strat {
+			strat[j] = 1.0 / float32(nActions)
+		}
+	}
+
+	c.values = make([][]float32, t.maxDepth+1)
+	c.legalStrats = make([][]float32, t.maxDepth+1)
+	for d := range c.values {
+		c.values[d] = make([]float32, maxActions)
+		c.legalStrats[d] = make([]float32, maxActions)
+	}
+
+	return c
+}
+
+// Iter returns the current iteration.
+func (c *CompiledCFR) Iter() int {
+	return c.iter
+}
+
+// Run performs one iteration of CFR, traversing the whole tree once for each
+// player and then updating all strategies. It returns the expected value of
+// player 0 under the strategies before the update.
+func (c *CompiledCFR) Run() float32 {
+	var ev float32
+	for player := 0; player < c.t.nPlayers; player++ {
+		v := c.traverse(0, 0, player, 1.0, 1.0)
+		if player == 0 {
+			ev = v
+		}
+	}
+
+	c.update()
+	return ev
+}
+
+// GetStrategy returns the current strategy at the given InfoSet.
+// The returned slice must not be modified.
+func (c *CompiledCFR) GetStrategy(infoSet int) []float32 {
+	return c.strategy[c.offset[infoSet] : c.offset[infoSet]+c.t.NumActions[infoSet]]
+}
+
+// GetAverageStrategy returns the average strategy at the given InfoSet.
+func (c *CompiledCFR) GetAverageStrategy(infoSet int) []float32 {
+	strategySum := c.strategySum[c.offset[infoSet] : c.offset[infoSet]+c.t.NumActions[infoSet]]
+	avgStrat := make([]float32, len(strategySum))
+	total := f32.Sum(strategySum)
+	if total > 0 {
+		f32.ScalUnitaryTo(avgStrat, 1.0/total, strategySum)
+	} else {
+		for i := range avgStrat {
+			avgStrat[i] = 1.0 / float32(len(avgStrat))
+		}
+	}
+
+	return avgStrat
+}
+
+// GetNodeAverageStrategy returns the average strategy at the InfoSet of
+// the given node, which must be in the compiled tree. It can be passed to
+// ExpectedValue.
+func (c *CompiledCFR) GetNodeAverageStrategy(node cfr.GameTreeNode) []float32 {
+	id, ok := c.t.InfoSetID(node.InfoSetKey(node.Player()))
+	if !ok {
+		panic(fmt.Errorf("infoset %q is not in the compiled tree", node.InfoSetKey(node.Player())))
+	}
+
+	return c.GetAverageStrategy(id)
+}
+
+// traverse returns the counterfactual value of the given node for player,
+// accumulating regrets and strategy weights at player's InfoSets.
+func (c *CompiledCFR) traverse(id int32, depth, player int, reachSelf, reachOthers float32) float32 {
+	t := c.t
+	start := t.ChildStart[id]
+	end := start + t.NumChildren[id]
+	switch t.Type[id] {
+	case cfr.TerminalNodeType:
+		return float32(t.Utility[int(t.UtilityStart[id])+player])
+	case cfr.ChanceNodeType:
+		var ev float32
+		for child := start; child < end; child++ {
+			p := float32(t.EdgeProb[child])
+			ev += p * c.traverse(child, depth+1, player, reachSelf, reachOthers*p)
+		}
+
+		return ev
+	}
+
+	strat := c.legalStrategy(id, depth)
+	if int(t.Player[id]) != player {
+		var ev float32
+		for i, p := range strat {
+			ev += p * c.traverse(start+int32(i), depth+1, player, reachSelf, reachOthers*p)
+		}
+
+		return ev
+	}
+
+	values := c.values[depth][:len(strat)]
+	for i, p := range strat {
+		values[i] = c.traverse(start+int32(i), depth+1, player, reachSelf*p, reachOthers)
+	}
+
+	ev := f32.DotUnitary(strat, values)
+	off := c.offset[t.InfoSet[id]]
+	regretSum := c.regretSum[off : off+int32(len(strat))]
+	for i, v := range values {
+		// Illegal actions accumulate no regret.
+		if t.EdgeLegal[start+int32(i)] {
+			regretSum[i] += reachOthers * (v - ev)
+		}
+	}
+
+	f32.AxpyUnitary(reachSelf, strat, c.strategyWeight[off:off+int32(len(strat))])
+	return ev
+}
+
+// legalStrategy returns the current strategy at the given node,
+// restricted to its legal actions.
+func (c *CompiledCFR) legalStrategy(id int32, depth int) []float32 {
+	t := c.t
+	strat := c.GetStrategy(int(t.InfoSet[id]))
+	if !t.Masked[id] {
+		return strat
+	}
+
+	start := t.ChildStart[id]
+	mask := t.EdgeLegal[start : start+int32(len(strat))]
+	legalStrat := c.legalStrats[depth][:len(strat)]
+	copy(legalStrat, strat)
+	cfr.ApplyMask(legalStrat, mask)
+	total := f32.Sum(legalStrat)
+	if total > 0 {
+		f32.ScalUnitary(1.0/total, legalStrat)
+	} else {
+		nLegal := float32(cfr.NumLegal(mask, len(mask)))
+		for i, legal := range mask {
+			if legal {
+				legalStrat[i] = 1.0 / nLegal
+			}
+		}
+	}
+
+	return legalStrat
+}
+
+// update discounts the accumulated regrets and strategies, and performs
+// regret matching at every InfoSet.
+func (c *CompiledCFR) update() {
+	discountPos, discountNeg, discountSum := c.params.GetDiscountFactors(c.iter)
+	if discountSum != 1.0 {
+		f32.ScalUnitary(discountSum, c.strategySum)
+	}
+
+	f32.Add(c.strategySum, c.strategyWeight)
+	for i := range c.strategyWeight {
+		c.strategyWeight[i] = 0 // memclr
+	}
+
+	for i, x := range c.regretSum {
+		if x > 0 {
+			c.regretSum[i] = x * discountPos
+		} else if x < 0 {
+			c.regretSum[i] = x * discountNeg
+		}
+	}
+
+	for i, nActions := range c.t.NumActions {
+		off := c.offset[i]
+		regretSum := c.regretSum[off : off+nActions]
+		strat := c.strategy[off : off+nActions]
+		var total float32
+		for j, x := range regretSum {
+			if x > 0 {
+				strat[j] = x
+				total += x
+			} else {
+				strat[j] = 0
+			}
+		}
+
+		if total > 0 {
+			f32.ScalUnitary(1.0/total, strat)
+		} else {
+			for j := range strat {
+				strat[j] = 1.0 / float32(nActions)
+			}
+		}
+	}
+
+	c.iter++
+}
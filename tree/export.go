@@ -0,0 +1,210 @@
+package tree
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// ExportOptions configure WriteDOT and WriteJSON.
+type ExportOptions struct {
+	// MaxDepth, if positive, is the maximum depth of the nodes written.
+	MaxDepth int
+	// Players is the number of players whose terminal utilities are
+	// written. If it is not positive, 2 is used.
+	Players int
+	// Profile, if non-nil, is used to annotate the edges out of each
+	// player node with the current and average strategy. Nodes whose
+	// infoset has no policy in the profile are not annotated.
+	Profile cfr.StrategyProfile
+}
+
+// exportedEdge is an edge to the ith child of a node.
+type exportedEdge struct {
+	Action          int       `json:"action"`
+	Probability     *float64  `json:"probability,omitempty"`
+	Strategy        *float32  `json:"strategy,omitempty"`
+	AverageStrategy *float32  `json:"averageStrategy,omitempty"`
+	Node            *exported `json:"node,omitempty"`
+}
+
+// exported is the exported representation of a node.
+type exported struct {
+	Type     string         `json:"type"`
+	Player   *int           `json:"player,omitempty"`
+	Label    string         `json:"label"`
+	InfoSet  string         `json:"infoSet,omitempty"`
+	Utility  []float64      `json:"utility,omitempty"`
+	Children []exportedEdge `json:"children,omitempty"`
+
+	id int
+}
+
+// export visits the tree rooted at root, up to opts.MaxDepth, and calls
+// visit with each node's exported representation and that of its parent.
+func export(root cfr.GameTreeNode, opts ExportOptions, visit func(node, parent *exported)) {
+	if opts.Players <= 0 {
+		opts.Players = 2
+	}
+
+	var stack []*exported
+	nextID := 0
+	Walk(context.Background(), root, WalkOptions{
+		PreOrder: func(node cfr.GameTreeNode, depth int) VisitAction {
+			e := exportNode(node, depth, opts)
+			e.id = nextID
+			nextID++
+
+			stack = append(stack[:depth], e)
+			var parent *exported
+			if depth > 0 {
+				parent = stack[depth-1]
+			}
+
+			visit(e, parent)
+			return Continue
+		},
+		MaxDepth:   opts.MaxDepth,
+		CloseNodes: true,
+	})
+}
+
+func exportNode(node cfr.GameTreeNode, depth int, opts ExportOptions) *exported {
+	e := &exported{
+		Type:  node.Type().String(),
+		Label: fmt.Sprint(node),
+	}
+
+	switch node.Type() {
+	case cfr.TerminalNodeType:
+		for p := 0; p < opts.Players; p++ {
+			e.Utility = append(e.Utility, node.Utility(p))
+		}
+
+		return e
+	case cfr.PlayerNodeType:
+		player := node.Player()
+		e.Player = &player
+		e.InfoSet = string(node.InfoSetKey(player))
+	}
+
+	if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+		return e
+	}
+
+	nChildren := node.NumChildren()
+	e.Children = make([]exportedEdge, nChildren)
+	for i := range e.Children {
+		e.Children[i].Action = i
+	}
+
+	if node.Type() == cfr.ChanceNodeType {
+		for i := range e.Children {
+			p := node.GetChildProbability(i)
+			e.Children[i].Probability = &p
+		}
+	} else if opts.Profile != nil {
+		// Infosets that the profile has not seen are left unannotated.
+		policy, ok := opts.Profile.GetPolicyByKey(e.InfoSet)
+		if !ok || len(policy.GetStrategy()) != nChildren {
+			return e
+		}
+
+		// The legal strategy may be scratch space reused by the policy.
+		strat := policy.GetLegalStrategy(node.LegalActions())
+		avgStrat := policy.GetAverageStrategy()
+		cfr.ApplyMask(avgStrat, node.LegalActions())
+		for i := range e.Children {
+			s, avg := strat[i], avgStrat[i]
+			e.Children[i].Strategy = &s
+			e.Children[i].AverageStrategy = &avg
+		}
+	}
+
+	return e
+}
+
+// WriteJSON writes the tree rooted at root to w as nested JSON objects,
+// one per node.
+func WriteJSON(w io.Writer, root cfr.GameTreeNode, opts ExportOptions) error {
+	var result *exported
+	export(root, opts, func(node, parent *exported) {
+		if parent == nil {
+			result = node
+			return
+		}
+
+		for i := range parent.Children {
+			if parent.Children[i].Node == nil {
+				parent.Children[i].Node = node
+				return
+			}
+		}
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+// WriteDOT writes the tree rooted at root to w in the Graphviz DOT language.
+func WriteDOT(w io.Writer, root cfr.GameTreeNode, opts ExportOptions) error {
+	var err error
+	printf := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	// Number of children of each node written so far, by id.
+	nWritten := make(map[int]int)
+	printf("digraph tree {\n")
+	export(root, opts, func(node, parent *exported) {
+		label := dotEscape(node.Label)
+		if node.Player != nil {
+			label = fmt.Sprintf("%s\\nplayer %d, infoset %s", label, *node.Player, dotEscape(node.InfoSet))
+		} else if node.Utility != nil {
+			label = fmt.Sprintf("%s\\nutility %v", label, node.Utility)
+		}
+
+		printf("  n%d [shape=%s, label=\"%s\"];\n", node.id, dotShape(node.Type), label)
+		if parent == nil {
+			return
+		}
+
+		edge := parent.Children[nWritten[parent.id]]
+		nWritten[parent.id]++
+		attrs := []string{fmt.Sprint(edge.Action)}
+		if edge.Probability != nil {
+			attrs = append(attrs, fmt.Sprintf("p=%.4g", *edge.Probability))
+		}
+		if edge.Strategy != nil {
+			attrs = append(attrs, fmt.Sprintf("σ=%.3f", *edge.Strategy))
+			attrs = append(attrs, fmt.Sprintf("avg=%.3f", *edge.AverageStrategy))
+		}
+
+		printf("  n%d -> n%d [label=\"%s\"];\n", parent.id, node.id, strings.Join(attrs, " "))
+	})
+	printf("}\n")
+
+	return err
+}
+
+func dotShape(nodeType string) string {
+	switch nodeType {
+	case cfr.ChanceNodeType.String():
+		return "circle"
+	case cfr.TerminalNodeType.String():
+		return "box"
+	default:
+		return "ellipse"
+	}
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,99 @@
+package tree
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// maskedNode is a testNode where player 1 may only take the action
+// matching the chance outcome, so nodes in the same infoset have
+// different legal actions.
+type maskedNode struct {
+	*testNode
+}
+
+func (n maskedNode) GetChild(i int) cfr.GameTreeNode {
+	return maskedNode{n.testNode.GetChild(i).(*testNode)}
+}
+
+func (n maskedNode) LegalActions() []bool {
+	if n.Type() != cfr.PlayerNodeType || n.Player() != 1 {
+		return nil
+	}
+
+	return []bool{n.history[0] == 'a', n.history[0] == 'b'}
+}
+
+func TestWriteJSON(t *testing.T) {
+	profile := cfr.NewPolicyTable(cfr.DiscountParams{})
+	profile.SetStrategy("1:b", []float32{0.25, 0.75})
+	var buf bytes.Buffer
+	err := WriteJSON(&buf, maskedNode{newTestTree(2, 3)}, ExportOptions{MaxDepth: 3, Profile: profile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var root exported
+	if err := json.Unmarshal(buf.Bytes(), &root); err != nil {
+		t.Fatal(err)
+	}
+
+	if root.Type != "chance" || len(root.Children) != 2 || *root.Children[0].Probability != 0.5 {
+		t.Errorf("unexpected root: %+v", root)
+	}
+
+	child := root.Children[1].Node
+	if child.Type != "player" || *child.Player != 0 || child.InfoSet != "0:b" {
+		t.Errorf("unexpected child: %+v", child)
+	}
+
+	// Infosets without a policy are not annotated, or added to the profile.
+	if child.Children[0].Strategy != nil {
+		t.Errorf("expected no strategy for an unseen infoset, got %+v", child.Children[0])
+	}
+	if n := len(profile.PoliciesByKey); n != 1 {
+		t.Errorf("expected export not to add policies, got %d", n)
+	}
+
+	// Both nodes of infoset 1:b keep the strategy for their own mask.
+	for i, want := range [][]float32{{1, 0}, {0, 1}} {
+		node := root.Children[i].Node.Children[1].Node
+		if node.InfoSet != "1:b" {
+			t.Fatalf("unexpected node: %+v", node)
+		}
+
+		for j, edge := range node.Children {
+			if *edge.Strategy != want[j] {
+				t.Errorf("%s: expected strategy %v, got %v for action %d", node.Label, want, *edge.Strategy, j)
+			}
+		}
+	}
+
+	if grandchild := child.Children[0].Node.Children[0].Node; grandchild.Children != nil {
+		t.Errorf("expected the tree to be cut off at depth 3, got %+v", grandchild)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDOT(&buf, newTestTree(2, 2), ExportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	dot := buf.String()
+	for _, expected := range []string{
+		"digraph tree {",
+		`n0 [shape=circle`,
+		`n0 -> n1 [label="0 p=0.5"];`,
+		`n1 -> n2 [label="0"];`,
+		`n2 [shape=box, label="aa\nutility [-0.5 0.5]"];`,
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("expected DOT output to contain %q:\n%s", expected, dot)
+		}
+	}
+}
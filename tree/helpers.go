@@ -1,49 +1,78 @@
 package tree
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+
 	"github.com/tam0705/go-cfr"
 )
 
 func Visit(root cfr.GameTreeNode, visitor func(node cfr.GameTreeNode)) {
-	visitor(root)
-	
-	for i := 0; i < root.NumChildren(); i++ {
-		Visit(root.GetChild(i), visitor)
-	}
-
-	root.Close()
+	Walk(context.Background(), root, WalkOptions{
+		PreOrder: func(node cfr.GameTreeNode, depth int) VisitAction {
+			visitor(node)
+			return Continue
+		},
+		CloseNodes: true,
+	})
 }
 
 func Visits(root cfr.GameTreeNode, height int) {
-	fmt.Println(strings.Repeat(" ", height), root)
-	
-	for i := 0; i < root.NumChildren(); i++ {
-		Visits(root.GetChild(i), height + 1)
-	}
+	Fprint(os.Stdout, root, height, 0)
+}
 
-	root.Close()
+// Fprint writes each node of the tree rooted at root to w, one per line,
+// indented by its depth plus indent. If maxDepth is positive, only nodes
+// up to that depth are written.
+func Fprint(w io.Writer, root cfr.GameTreeNode, indent, maxDepth int) error {
+	var err error
+	Walk(context.Background(), root, WalkOptions{
+		PreOrder: func(node cfr.GameTreeNode, depth int) VisitAction {
+			if _, err = fmt.Fprintln(w, strings.Repeat(" ", indent+depth), node); err != nil {
+				return Stop
+			}
+
+			return Continue
+		},
+		MaxDepth:   maxDepth,
+		CloseNodes: true,
+	})
+
+	return err
 }
 
 func VisitInfoSets(root cfr.GameTreeNode, visitor func(n cfr.GameTreeNode, player int, infoSet cfr.InfoSet)) {
+	VisitInfoSetsContext(context.Background(), root, visitor)
+}
+
+// VisitInfoSetsContext is like VisitInfoSets, but stops early if ctx is
+// cancelled, in which case it returns ctx.Err().
+func VisitInfoSetsContext(ctx context.Context, root cfr.GameTreeNode, visitor func(n cfr.GameTreeNode, player int, infoSet cfr.InfoSet)) error {
 	seen := make(map[string]struct{})
-	Visit(root, func(node cfr.GameTreeNode) {
-		if node == nil {
-			return
-		}
-		if node.Type() == cfr.PlayerNodeType {
-			n := node
-			player := node.Player()
-			infoSet := node.InfoSet(player)
-			key := infoSet.Key()
-			if _, ok := seen[string(key)]; ok {
-				return
+	return Walk(ctx, root, WalkOptions{
+		PreOrder: func(node cfr.GameTreeNode, depth int) VisitAction {
+			if node == nil {
+				return Skip
 			}
+			if node.Type() == cfr.PlayerNodeType {
+				n := node
+				player := node.Player()
+				infoSet := node.InfoSet(player)
+				key := infoSet.Key()
+				if _, ok := seen[string(key)]; ok {
+					return Continue
+				}
 
-			visitor(n, player, infoSet)
-			seen[string(key)] = struct{}{}
-		}
+				visitor(n, player, infoSet)
+				seen[string(key)] = struct{}{}
+			}
+
+			return Continue
+		},
+		CloseNodes: true,
 	})
 }
 
@@ -69,3 +98,11 @@ func CountInfoSets(root cfr.GameTreeNode) int {
 	VisitInfoSets(root, func(node cfr.GameTreeNode, player int, infoSet cfr.InfoSet) { total++ })
 	return total
 }
+
+// CountInfoSetsContext is like CountInfoSets, but stops early if ctx is
+// cancelled, in which case it returns the count so far and ctx.Err().
+func CountInfoSetsContext(ctx context.Context, root cfr.GameTreeNode) (int, error) {
+	total := 0
+	err := VisitInfoSetsContext(ctx, root, func(node cfr.GameTreeNode, player int, infoSet cfr.InfoSet) { total++ })
+	return total, err
+}
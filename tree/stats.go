@@ -0,0 +1,232 @@
+package tree
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// ChancePlayer is the player used for chance nodes in Stats.Branching.
+const ChancePlayer = -1
+
+// StatsOptions configure ComputeStats.
+type StatsOptions struct {
+	// Workers is the number of goroutines that traverse the tree.
+	// If it is not positive, runtime.GOMAXPROCS(0) is used.
+	Workers int
+	// Players is the number of players whose terminal utilities are
+	// recorded. If it is not positive, 2 is used.
+	Players int
+	// Street, if non-nil, returns the street (or round) of a player node,
+	// used to count InfoSets per street.
+	Street func(node cfr.GameTreeNode) int
+}
+
+// Stats summarizes the structure of a game tree.
+type Stats struct {
+	// NodesByType is the number of nodes of each NodeType.
+	NodesByType [3]int
+	// NodesByDepth is the number of nodes of each NodeType at each depth.
+	NodesByDepth [][3]int
+	// Branching is a histogram of the number of children of each
+	// player's nodes (and of chance nodes, under ChancePlayer).
+	Branching map[int]map[int]int
+	// InfoSetsByPlayer is the number of distinct InfoSets of each player.
+	InfoSetsByPlayer map[int]int
+	// InfoSetsByStreet is the number of distinct InfoSets on each street,
+	// if StatsOptions.Street was given.
+	InfoSetsByStreet map[int]int
+	// MinUtility and MaxUtility are the range of each player's utility
+	// over all terminal nodes.
+	MinUtility, MaxUtility []float64
+
+	// InfoSets seen, by hash of their key.
+	infoSets map[uint64]infoSetClass
+}
+
+type infoSetClass struct {
+	player, street int
+}
+
+func newStats(nPlayers int) *Stats {
+	s := &Stats{
+		Branching:        make(map[int]map[int]int),
+		InfoSetsByPlayer: make(map[int]int),
+		InfoSetsByStreet: make(map[int]int),
+		MinUtility:       make([]float64, nPlayers),
+		MaxUtility:       make([]float64, nPlayers),
+		infoSets:         make(map[uint64]infoSetClass),
+	}
+
+	for i := range s.MinUtility {
+		s.MinUtility[i] = math.Inf(1)
+		s.MaxUtility[i] = math.Inf(-1)
+	}
+
+	return s
+}
+
+func (s *Stats) add(node cfr.GameTreeNode, depth int, opts StatsOptions) {
+	t := node.Type()
+	s.NodesByType[t]++
+	for len(s.NodesByDepth) <= depth {
+		s.NodesByDepth = append(s.NodesByDepth, [3]int{})
+	}
+	s.NodesByDepth[depth][t]++
+
+	switch t {
+	case cfr.TerminalNodeType:
+		for p := range s.MinUtility {
+			u := node.Utility(p)
+			s.MinUtility[p] = math.Min(s.MinUtility[p], u)
+			s.MaxUtility[p] = math.Max(s.MaxUtility[p], u)
+		}
+	case cfr.ChanceNodeType:
+		s.addBranching(ChancePlayer, node.NumChildren(), 1)
+	default:
+		player := node.Player()
+		s.addBranching(player, node.NumChildren(), 1)
+
+		h := fnv.New64a()
+		h.Write(node.InfoSetKey(player))
+		class := infoSetClass{player: player}
+		if opts.Street != nil {
+			class.street = opts.Street(node)
+		}
+
+		s.infoSets[h.Sum64()] = class
+	}
+}
+
+func (s *Stats) addBranching(player, nChildren, count int) {
+	hist, ok := s.Branching[player]
+	if !ok {
+		hist = make(map[int]int)
+		s.Branching[player] = hist
+	}
+
+	hist[nChildren] += count
+}
+
+// merge adds the statistics in other to s.
+func (s *Stats) merge(other *Stats) {
+	for t, n := range other.NodesByType {
+		s.NodesByType[t] += n
+	}
+
+	for len(s.NodesByDepth) < len(other.NodesByDepth) {
+		s.NodesByDepth = append(s.NodesByDepth, [3]int{})
+	}
+	for d, counts := range other.NodesByDepth {
+		for t, n := range counts {
+			s.NodesByDepth[d][t] += n
+		}
+	}
+
+	for player, hist := range other.Branching {
+		for nChildren, n := range hist {
+			s.addBranching(player, nChildren, n)
+		}
+	}
+
+	for p := range s.MinUtility {
+		s.MinUtility[p] = math.Min(s.MinUtility[p], other.MinUtility[p])
+		s.MaxUtility[p] = math.Max(s.MaxUtility[p], other.MaxUtility[p])
+	}
+
+	for h, class := range other.infoSets {
+		s.infoSets[h] = class
+	}
+}
+
+// countInfoSets fills in InfoSetsByPlayer and InfoSetsByStreet.
+func (s *Stats) countInfoSets(opts StatsOptions) {
+	for _, class := range s.infoSets {
+		s.InfoSetsByPlayer[class.player]++
+		if opts.Street != nil {
+			s.InfoSetsByStreet[class.street]++
+		}
+	}
+}
+
+// ComputeStats traverses the tree rooted at root and returns its Stats.
+//
+// The subtrees of the root's children are split among several workers,
+// so the GameTreeNode implementation must support traversing distinct
+// subtrees concurrently. InfoSets are identified by a 64-bit hash of
+// their key to save memory, so the counts may (very rarely) be low.
+// Each node is closed after it has been visited.
+func ComputeStats(ctx context.Context, root cfr.GameTreeNode, opts StatsOptions) (*Stats, error) {
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.GOMAXPROCS(0)
+	}
+	if opts.Players <= 0 {
+		opts.Players = 2
+	}
+
+	result := newStats(opts.Players)
+	result.add(root, 0, opts)
+
+	// Build the root's children up front, so that the workers
+	// only ever touch their own subtrees.
+	nChildren := root.NumChildren()
+	children := make([]cfr.GameTreeNode, nChildren)
+	for i := range children {
+		children[i] = root.GetChild(i)
+	}
+
+	work := make(chan cfr.GameTreeNode)
+	workerStats := make([]*Stats, opts.Workers)
+	errs := make([]error, opts.Workers)
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Workers; w++ {
+		workerStats[w] = newStats(opts.Players)
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for child := range work {
+				errs[w] = Walk(ctx, child, WalkOptions{
+					PreOrder: func(node cfr.GameTreeNode, depth int) VisitAction {
+						workerStats[w].add(node, depth+1, opts)
+						return Continue
+					},
+					CloseNodes: true,
+				})
+
+				if errs[w] != nil {
+					return
+				}
+			}
+		}(w)
+	}
+
+	var err error
+	for _, child := range children {
+		select {
+		case work <- child:
+			continue
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+
+		break
+	}
+
+	close(work)
+	wg.Wait()
+	root.Close()
+
+	for w, s := range workerStats {
+		result.merge(s)
+		if errs[w] != nil {
+			err = errs[w]
+		}
+	}
+
+	result.countInfoSets(opts)
+	return result, err
+}
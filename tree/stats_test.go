@@ -0,0 +1,49 @@
+package tree
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+)
+
+func TestComputeStats(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		stats, err := ComputeStats(context.Background(), newTestTree(3, 5), StatsOptions{
+			Workers: workers,
+			Street: func(node cfr.GameTreeNode) int {
+				return len(node.(*testNode).history) / 3
+			},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := [3]int{1, 243, 3 + 9 + 27 + 81}
+		if stats.NodesByType != expected {
+			t.Errorf("workers=%d: expected %v nodes by type, got %v", workers, expected, stats.NodesByType)
+		}
+
+		if n := stats.NodesByDepth[5][cfr.TerminalNodeType]; n != 243 {
+			t.Errorf("workers=%d: expected 243 terminal nodes at depth 5, got %d", workers, n)
+		}
+
+		if n := stats.Branching[0][3]; n != 3+27 {
+			t.Errorf("workers=%d: expected 30 player 0 nodes with 3 children, got %d", workers, n)
+		}
+
+		if stats.InfoSetsByPlayer[0] != 30 || stats.InfoSetsByPlayer[1] != 30 {
+			t.Errorf("workers=%d: expected 30 infosets per player, got %v", workers, stats.InfoSetsByPlayer)
+		}
+
+		// Depths 1-2 are street 0, depths 3-4 street 1.
+		if stats.InfoSetsByStreet[0] != 6 || stats.InfoSetsByStreet[1] != 54 {
+			t.Errorf("workers=%d: unexpected infosets by street: %v", workers, stats.InfoSetsByStreet)
+		}
+
+		if stats.MinUtility[0] != -1 || stats.MaxUtility[0] != 1 || stats.MinUtility[1] != -1 {
+			t.Errorf("workers=%d: unexpected utility ranges: %v %v", workers, stats.MinUtility, stats.MaxUtility)
+		}
+	}
+}
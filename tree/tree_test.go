@@ -0,0 +1,120 @@
+package tree
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// testNode is a complete tree with the given branching factor and height.
+// The root is a chance node with uniform probabilities, players 0 and 1
+// alternate below it, and the leaves are terminal. Player 1 does not
+// observe the chance outcome.
+type testNode struct {
+	parent    *testNode
+	history   string
+	branching int
+	height    int
+	children  []*testNode
+	closed    *int64
+}
+
+func newTestTree(branching, height int) *testNode {
+	return &testNode{branching: branching, height: height, closed: new(int64)}
+}
+
+func (n *testNode) depth() int { return len(n.history) }
+
+func (n *testNode) String() string { return n.history }
+
+func (n *testNode) GetNode(history string) cfr.GameTreeNode { return nil }
+
+func (n *testNode) Type() cfr.NodeType {
+	switch {
+	case n.depth() == n.height:
+		return cfr.TerminalNodeType
+	case n.depth() == 0:
+		return cfr.ChanceNodeType
+	default:
+		return cfr.PlayerNodeType
+	}
+}
+
+func (n *testNode) Close() {
+	n.children = nil
+	atomic.AddInt64(n.closed, 1)
+}
+
+func (n *testNode) NumChildren() int {
+	if n.Type() == cfr.TerminalNodeType {
+		return 0
+	}
+
+	return n.branching
+}
+
+func (n *testNode) GetChild(i int) cfr.GameTreeNode {
+	if n.children == nil {
+		for j := 0; j < n.branching; j++ {
+			n.children = append(n.children, &testNode{
+				parent:    n,
+				history:   n.history + string(rune('a'+j)),
+				branching: n.branching,
+				height:    n.height,
+				closed:    n.closed,
+			})
+		}
+	}
+
+	return n.children[i]
+}
+
+func (n *testNode) Parent() cfr.GameTreeNode {
+	if n.parent == nil {
+		return nil
+	}
+
+	return n.parent
+}
+
+func (n *testNode) GetChildProbability(i int) float64 { return 1.0 / float64(n.branching) }
+
+func (n *testNode) SampleChild() (cfr.GameTreeNode, float64) {
+	return n.GetChild(0), n.GetChildProbability(0)
+}
+
+func (n *testNode) Player() int { return (n.depth() + 1) % 2 }
+
+func (n *testNode) InfoSet(player int) cfr.InfoSet {
+	s := testInfoSet(n.InfoSetKey(player))
+	return &s
+}
+
+func (n *testNode) InfoSetKey(player int) []byte {
+	if player == 1 {
+		return []byte(fmt.Sprintf("%d:%s", player, n.history[1:]))
+	}
+
+	return []byte(fmt.Sprintf("%d:%s", player, n.history))
+}
+
+func (n *testNode) LegalActions() []bool { return nil }
+
+func (n *testNode) Utility(player int) float64 {
+	u := float64(n.history[len(n.history)-1]-'a') - float64(n.branching-1)/2
+	if player == 1 {
+		return -u
+	}
+
+	return u
+}
+
+type testInfoSet string
+
+func (s testInfoSet) Key() []byte                    { return []byte(s) }
+func (s testInfoSet) MarshalBinary() ([]byte, error) { return []byte(s), nil }
+func (s *testInfoSet) UnmarshalBinary(buf []byte) error {
+	*s = testInfoSet(buf)
+	return nil
+}
@@ -0,0 +1,232 @@
+package tree
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// Problem is a structural problem in a game tree found by Validate.
+type Problem struct {
+	// Path is the sequence of child indices leading from the root to the node.
+	Path []int
+	// Node is the string representation of the node.
+	Node string
+	// Message describes the problem.
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%v (%s): %s", p.Path, p.Node, p.Message)
+}
+
+// ValidateOptions configure ValidateWithOptions.
+type ValidateOptions struct {
+	// Players is the number of players whose terminal utilities are
+	// checked. If it is not positive, 2 is used.
+	Players int
+	// ZeroSum declares that the game is zero-sum, so that the players'
+	// utilities at each terminal node must sum to 0.
+	ZeroSum bool
+	// Tolerance is the allowed floating point error in probability and
+	// utility sums. If it is not positive, 1e-3 is used.
+	Tolerance float64
+	// MaxProblems, if positive, stops validation after that many problems.
+	MaxProblems int
+}
+
+// Validate checks the structure of the game tree rooted at root with
+// the default options, and returns any problems found.
+func Validate(root cfr.GameTreeNode) []Problem {
+	return ValidateWithOptions(context.Background(), root, ValidateOptions{})
+}
+
+type infoSetShape struct {
+	nChildren int
+	path      []int
+}
+
+// ValidateWithOptions checks the structure of the game tree rooted at root,
+// and returns any problems found. It checks that:
+//   - Chance node probabilities are in [0, 1] and sum to 1.
+//   - All nodes sharing an InfoSet key have the same number of children.
+//   - Each child's Parent() is the node it was reached from.
+//   - Terminal utilities are finite, and sum to 0 if the game is zero-sum.
+//   - Terminal nodes have no children and other nodes have some.
+//   - Legal action masks match the number of children and allow some action.
+//
+// Each node is closed after it has been visited.
+func ValidateWithOptions(ctx context.Context, root cfr.GameTreeNode, opts ValidateOptions) []Problem {
+	if opts.Players <= 0 {
+		opts.Players = 2
+	}
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = 1e-3
+	}
+
+	var problems []Problem
+	// indices[d] and ancestors[d] are the child index and node at depth d
+	// along the path to the current node.
+	var indices []int
+	var ancestors []cfr.GameTreeNode
+	infoSets := make(map[string]infoSetShape)
+
+	Walk(ctx, root, WalkOptions{
+		PreOrder: func(node cfr.GameTreeNode, depth int) VisitAction {
+			if depth == len(indices) {
+				indices = append(indices, 0)
+				ancestors = append(ancestors, node)
+			} else {
+				indices = indices[:depth+1]
+				indices[depth]++
+				ancestors = ancestors[:depth+1]
+				ancestors[depth] = node
+			}
+
+			report := func(format string, args ...interface{}) {
+				path := append([]int(nil), indices[1:]...)
+				problems = append(problems, Problem{
+					Path:    path,
+					Node:    fmt.Sprint(node),
+					Message: fmt.Sprintf(format, args...),
+				})
+			}
+
+			validateNode(node, opts, report)
+			if depth > 0 && !sameNode(node.Parent(), ancestors[depth-1]) {
+				report("Parent() is not the node it was reached from")
+			}
+
+			if node.Type() == cfr.PlayerNodeType {
+				player := node.Player()
+				infoSetKey := node.InfoSetKey(player)
+				key := fmt.Sprintf("%d:%s", player, infoSetKey)
+				shape, ok := infoSets[key]
+				if !ok {
+					infoSets[key] = infoSetShape{node.NumChildren(), append([]int(nil), indices[1:]...)}
+				} else if shape.nChildren != node.NumChildren() {
+					report("has %d children, but player %d's InfoSet %q has %d children at %v",
+						node.NumChildren(), player, infoSetKey, shape.nChildren, shape.path)
+				}
+			}
+
+			if opts.MaxProblems > 0 && len(problems) >= opts.MaxProblems {
+				return Stop
+			}
+
+			return Continue
+		},
+		CloseNodes: true,
+	})
+
+	return problems
+}
+
+// sameNode returns whether a and b are the same node. Pointers are compared
+// by address. Values of types that cannot be compared with == (such as
+// structs holding slices) are compared by their identity in the tree:
+// what they are, and the identity of their parents up to the root.
+func sameNode(a, b cfr.GameTreeNode) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() {
+		return va.IsValid() == vb.IsValid()
+	}
+
+	if va.Type() != vb.Type() {
+		return false
+	}
+
+	if va.Kind() == reflect.Ptr {
+		return va.Pointer() == vb.Pointer()
+	}
+
+	if equal, ok := tryEqual(a, b); ok {
+		return equal
+	}
+
+	return sameShape(a, b) && sameNode(a.Parent(), b.Parent())
+}
+
+// tryEqual returns a == b, and false if the comparison panicked.
+func tryEqual(a, b cfr.GameTreeNode) (equal, ok bool) {
+	defer func() {
+		if recover() != nil {
+			equal, ok = false, false
+		}
+	}()
+
+	return a == b, true
+}
+
+// sameShape returns whether a and b look like the same node, without
+// considering their parents.
+func sameShape(a, b cfr.GameTreeNode) bool {
+	if a.Type() != b.Type() || a.NumChildren() != b.NumChildren() || fmt.Sprint(a) != fmt.Sprint(b) {
+		return false
+	}
+
+	if a.Type() == cfr.PlayerNodeType {
+		player := a.Player()
+		return player == b.Player() && string(a.InfoSetKey(player)) == string(b.InfoSetKey(player))
+	}
+
+	return true
+}
+
+func validateNode(node cfr.GameTreeNode, opts ValidateOptions, report func(format string, args ...interface{})) {
+	nChildren := node.NumChildren()
+	switch node.Type() {
+	case cfr.TerminalNodeType:
+		if nChildren > 0 {
+			report("terminal node has %d children", nChildren)
+		}
+
+		var total float64
+		for p := 0; p < opts.Players; p++ {
+			u := node.Utility(p)
+			if math.IsNaN(u) || math.IsInf(u, 0) {
+				report("utility for player %d is %v", p, u)
+			}
+
+			total += u
+		}
+
+		if opts.ZeroSum && math.Abs(total) > opts.Tolerance {
+			report("utilities sum to %v in a zero-sum game", total)
+		}
+	case cfr.ChanceNodeType:
+		if nChildren == 0 {
+			report("chance node has no children")
+			return
+		}
+
+		var total float64
+		for i := 0; i < nChildren; i++ {
+			p := node.GetChildProbability(i)
+			if p < 0 || p > 1 || math.IsNaN(p) {
+				report("child %d has probability %v", i, p)
+			}
+
+			total += p
+		}
+
+		if math.Abs(total-1.0) > opts.Tolerance {
+			report("child probabilities sum to %v", total)
+		}
+	default:
+		if nChildren == 0 {
+			report("player node has no children")
+			return
+		}
+
+		mask := node.LegalActions()
+		if mask != nil && len(mask) != nChildren {
+			report("legal action mask has %d entries but node has %d children", len(mask), nChildren)
+		} else if cfr.NumLegal(mask, nChildren) == 0 {
+			report("no legal actions")
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package tree
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+)
+
+func TestValidate(t *testing.T) {
+	problems := ValidateWithOptions(context.Background(), newTestTree(3, 4), ValidateOptions{ZeroSum: true})
+	if len(problems) > 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+}
+
+// valueNode wraps a testNode in a value type that cannot be compared.
+// The nodes listed in wrongParent return their parent's sibling as parent.
+type valueNode struct {
+	*testNode
+	wrongParent []string
+}
+
+func (n valueNode) GetChild(i int) cfr.GameTreeNode {
+	return valueNode{n.testNode.GetChild(i).(*testNode), n.wrongParent}
+}
+
+func (n valueNode) Parent() cfr.GameTreeNode {
+	if n.parent == nil {
+		return nil
+	}
+
+	for _, history := range n.wrongParent {
+		if n.history == history {
+			sibling := n.parent.parent.GetChild(1 - int(n.parent.history[len(n.parent.history)-1]-'a'))
+			return valueNode{sibling.(*testNode), n.wrongParent}
+		}
+	}
+
+	return valueNode{n.parent, n.wrongParent}
+}
+
+func TestValidateValueNodes(t *testing.T) {
+	if problems := Validate(valueNode{testNode: newTestTree(2, 3)}); len(problems) > 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+
+	problems := Validate(valueNode{newTestTree(2, 3), []string{"ab"}})
+	if len(problems) != 1 || problems[0].Message != "Parent() is not the node it was reached from" ||
+		!reflect.DeepEqual(problems[0].Path, []int{0, 1}) {
+		t.Errorf("expected a parent problem at [0 1], got %v", problems)
+	}
+}
+
+// brokenNode wraps a testNode, with one fewer child at the node "ab",
+// and chance probabilities that do not sum to 1.
+type brokenNode struct {
+	*testNode
+}
+
+func (n brokenNode) NumChildren() int {
+	if n.history == "ab" {
+		return n.testNode.NumChildren() - 1
+	}
+
+	return n.testNode.NumChildren()
+}
+
+func (n brokenNode) GetChild(i int) cfr.GameTreeNode {
+	return brokenNode{n.testNode.GetChild(i).(*testNode)}
+}
+
+func (n brokenNode) GetChildProbability(i int) float64 {
+	return 0.5
+}
+
+func TestValidateFindsProblems(t *testing.T) {
+	problems := Validate(brokenNode{newTestTree(2, 3)})
+	for _, p := range problems {
+		t.Log(p)
+	}
+
+	// The chance probabilities sum to 1.0, so the problems are:
+	// the parent links (the wrapper is not the wrapped parent), and
+	// the InfoSet "1:b" reached with both 1 and 2 children.
+	var foundInfoSet, foundParent bool
+	for _, p := range problems {
+		switch p.Message {
+		case "Parent() is not the node it was reached from":
+			foundParent = true
+		case `has 2 children, but player 1's InfoSet "1:b" has 1 children at [0 1]`:
+			foundInfoSet = true
+		}
+	}
+
+	if !foundInfoSet || !foundParent {
+		t.Errorf("expected InfoSet and parent problems, got %v", problems)
+	}
+
+	problems = Validate(brokenNode{newTestTree(3, 2)})
+	if len(problems) == 0 || problems[0].Message != "child probabilities sum to 1.5" {
+		t.Errorf("expected chance probability problem, got %v", problems)
+	}
+}
@@ -0,0 +1,48 @@
+package tree
+
+import (
+	"github.com/tam0705/go-cfr"
+)
+
+// ExpectedValue returns the expected utility of player 0 in the game tree
+// rooted at root, when each player node is played according to the
+// distribution returned by averageStrategy.
+func ExpectedValue(root cfr.GameTreeNode, averageStrategy func(cfr.GameTreeNode) []float32) float64 {
+	var ev float64
+	switch root.Type() {
+	case cfr.TerminalNodeType:
+		return root.Utility(0)
+	case cfr.ChanceNodeType:
+		for i := 0; i < root.NumChildren(); i++ {
+			ev += root.GetChildProbability(i) * ExpectedValue(root.GetChild(i), averageStrategy)
+		}
+	default:
+		for i, p := range averageStrategy(root) {
+			if p > 0 {
+				ev += float64(p) * ExpectedValue(root.GetChild(i), averageStrategy)
+			}
+		}
+	}
+
+	return ev
+}
+
+// ProfileAverageStrategy returns a function that looks up the average
+// strategy of a node in profile, for use with ExpectedValue. Nodes whose
+// infoset has no policy in the profile play uniformly, and are not added
+// to the profile.
+func ProfileAverageStrategy(profile cfr.StrategyProfile) func(cfr.GameTreeNode) []float32 {
+	return func(node cfr.GameTreeNode) []float32 {
+		policy, ok := profile.GetPolicyByKey(string(node.InfoSetKey(node.Player())))
+		if !ok {
+			uniform := make([]float32, node.NumChildren())
+			for i := range uniform {
+				uniform[i] = 1.0 / float32(len(uniform))
+			}
+
+			return uniform
+		}
+
+		return policy.GetAverageStrategy()
+	}
+}
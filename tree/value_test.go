@@ -0,0 +1,32 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+)
+
+func TestExpectedValue(t *testing.T) {
+	// The utility is given by player 1's action, so it decides the value.
+	ev := ExpectedValue(newTestTree(2, 3), func(node cfr.GameTreeNode) []float32 {
+		if node.Player() == 1 {
+			return []float32{1, 0}
+		}
+
+		return []float32{0.5, 0.5}
+	})
+
+	if ev != -0.5 {
+		t.Errorf("expected value -0.5, got %v", ev)
+	}
+
+	// Infosets missing from the profile are played uniformly.
+	profile := cfr.NewPolicyTable(cfr.DiscountParams{})
+	if ev := ExpectedValue(newTestTree(2, 3), ProfileAverageStrategy(profile)); ev != 0 {
+		t.Errorf("expected value 0, got %v", ev)
+	}
+
+	if n := len(profile.PoliciesByKey); n != 0 {
+		t.Errorf("expected no policies to be added to the profile, got %d", n)
+	}
+}
@@ -0,0 +1,108 @@
+package tree
+
+import (
+	"context"
+
+	"github.com/tam0705/go-cfr"
+)
+
+// VisitAction is returned by a WalkFunc to control the rest of the traversal.
+type VisitAction int
+
+const (
+	// Continue the traversal as normal.
+	Continue VisitAction = iota
+	// Skip the children of the current node. It has no effect when
+	// returned after the children have been visited.
+	Skip
+	// Stop the traversal.
+	Stop
+)
+
+// WalkFunc is called for each node visited by Walk, along with its depth
+// (the root is at depth 0).
+type WalkFunc func(node cfr.GameTreeNode, depth int) VisitAction
+
+// WalkOptions configure a traversal by Walk.
+type WalkOptions struct {
+	// PreOrder, if non-nil, is called for each node before its children.
+	PreOrder WalkFunc
+	// PostOrder, if non-nil, is called for each node after its children.
+	PostOrder WalkFunc
+	// MaxDepth, if positive, is the maximum depth of the nodes visited.
+	MaxDepth int
+	// CloseNodes determines whether Close is called on each node after
+	// its children have been visited.
+	CloseNodes bool
+}
+
+type walkFrame struct {
+	node      cfr.GameTreeNode
+	depth     int
+	nextChild int
+	nChildren int
+}
+
+// Walk traverses the tree rooted at root depth-first, using an explicit
+// stack rather than recursion. It stops early if a visitor returns Stop,
+// or if ctx is cancelled, in which case it returns ctx.Err().
+//
+// Nodes remaining on the stack when the traversal stops are still closed
+// if opts.CloseNodes is set.
+func Walk(ctx context.Context, root cfr.GameTreeNode, opts WalkOptions) error {
+	done := ctx.Done()
+	var stack []walkFrame
+	var err error
+
+	push := func(node cfr.GameTreeNode, depth int) VisitAction {
+		action := Continue
+		if opts.PreOrder != nil {
+			action = opts.PreOrder(node, depth)
+		}
+
+		frame := walkFrame{node: node, depth: depth}
+		if action == Continue && (opts.MaxDepth <= 0 || depth < opts.MaxDepth) {
+			frame.nChildren = node.NumChildren()
+		}
+
+		stack = append(stack, frame)
+		return action
+	}
+
+	stopped := push(root, 0) == Stop
+	for len(stack) > 0 && !stopped {
+		select {
+		case <-done:
+			err = ctx.Err()
+			stopped = true
+			continue
+		default:
+		}
+
+		top := &stack[len(stack)-1]
+		if top.nextChild < top.nChildren {
+			child := top.node.GetChild(top.nextChild)
+			top.nextChild++
+			stopped = push(child, top.depth+1) == Stop
+			continue
+		}
+
+		frame := *top
+		stack = stack[:len(stack)-1]
+		if opts.PostOrder != nil && opts.PostOrder(frame.node, frame.depth) == Stop {
+			stopped = true
+		}
+
+		if opts.CloseNodes {
+			frame.node.Close()
+		}
+	}
+
+	if opts.CloseNodes {
+		for i := len(stack) - 1; i >= 0; i-- {
+			stack[i].node.Close()
+		}
+	}
+
+	return err
+}
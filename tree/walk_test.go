@@ -0,0 +1,116 @@
+package tree
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/tam0705/go-cfr"
+)
+
+func TestWalk(t *testing.T) {
+	root := newTestTree(3, 4)
+	var pre, post []int
+	err := Walk(context.Background(), root, WalkOptions{
+		PreOrder: func(node cfr.GameTreeNode, depth int) VisitAction {
+			pre = append(pre, depth)
+			return Continue
+		},
+		PostOrder: func(node cfr.GameTreeNode, depth int) VisitAction {
+			post = append(post, depth)
+			return Continue
+		},
+		CloseNodes: true,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const nNodes = 1 + 3 + 9 + 27 + 81
+	if len(pre) != nNodes || len(post) != nNodes {
+		t.Errorf("expected %d nodes, got %d pre-order and %d post-order", nNodes, len(pre), len(post))
+	}
+
+	if pre[0] != 0 || pre[1] != 1 || post[0] != 4 || post[len(post)-1] != 0 {
+		t.Errorf("unexpected visit order: pre=%v post=%v", pre[:5], post[:5])
+	}
+
+	if atomic.LoadInt64(root.closed) != nNodes {
+		t.Errorf("expected %d nodes to be closed, got %d", nNodes, atomic.LoadInt64(root.closed))
+	}
+}
+
+func TestWalkMaxDepthAndSkip(t *testing.T) {
+	root := newTestTree(2, 6)
+	n := 0
+	Walk(context.Background(), root, WalkOptions{
+		PreOrder: func(node cfr.GameTreeNode, depth int) VisitAction {
+			n++
+			if depth == 1 && node.(*testNode).history == "a" {
+				return Skip
+			}
+
+			return Continue
+		},
+		MaxDepth: 3,
+	})
+
+	// Depth 0-3 of the complete binary tree, minus the 6 nodes under "a".
+	if expected := 1 + 2 + 4 + 8 - 6; n != expected {
+		t.Errorf("expected %d nodes, got %d", expected, n)
+	}
+
+	if atomic.LoadInt64(root.closed) != 0 {
+		t.Errorf("expected no nodes to be closed, got %d", atomic.LoadInt64(root.closed))
+	}
+}
+
+func TestWalkStopAndCancel(t *testing.T) {
+	root := newTestTree(2, 10)
+	n := 0
+	err := Walk(context.Background(), root, WalkOptions{
+		PreOrder: func(node cfr.GameTreeNode, depth int) VisitAction {
+			n++
+			if n == 5 {
+				return Stop
+			}
+
+			return Continue
+		},
+		CloseNodes: true,
+	})
+
+	if err != nil || n != 5 {
+		t.Errorf("expected to stop after 5 nodes without error, got %d and %v", n, err)
+	}
+
+	if atomic.LoadInt64(root.closed) != 5 {
+		t.Errorf("expected the 5 visited nodes to be closed, got %d", atomic.LoadInt64(root.closed))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n = 0
+	err = Walk(ctx, newTestTree(2, 20), WalkOptions{
+		PreOrder: func(node cfr.GameTreeNode, depth int) VisitAction {
+			n++
+			if n == 100 {
+				cancel()
+			}
+
+			return Continue
+		},
+	})
+
+	if err != context.Canceled || n != 100 {
+		t.Errorf("expected cancellation after 100 nodes, got %d and %v", n, err)
+	}
+}
+
+func TestCountInfoSets(t *testing.T) {
+	// Player 0 sees the chance outcome: 3 + 3^3 infosets at depths 1 and 3.
+	// Player 1 does not: 3 + 3^3 at depths 2 and 4 collapse to 3 and 27.
+	if n := CountInfoSets(newTestTree(3, 5)); n != 3+27+3+27 {
+		t.Errorf("expected %d infosets, got %d", 3+27+3+27, n)
+	}
+}